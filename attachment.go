@@ -0,0 +1,244 @@
+package instabot
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// AttachmentCache records ids of uploaded attachments by key,
+// so that the same asset is never uploaded twice.
+// Attachment ids are only valid for the page they were uploaded by,
+// a cache must not be shared between clients of different pages.
+type AttachmentCache interface {
+	// Get returns attachment id recorded for key and reports whether there is one.
+	Get(ctx context.Context, key string) (string, bool, error)
+	// Set records attachment id for key.
+	Set(ctx context.Context, key string, attachmentID string) error
+}
+
+// MemoryAttachmentCache defines in memory AttachmentCache.
+type MemoryAttachmentCache struct {
+	mu    sync.Mutex
+	items map[string]string
+}
+
+// compile time interface implementation check.
+var _ AttachmentCache = (*MemoryAttachmentCache)(nil)
+
+// NewMemoryAttachmentCache returns a new in memory attachment cache.
+func NewMemoryAttachmentCache() *MemoryAttachmentCache {
+	return &MemoryAttachmentCache{
+		items: make(map[string]string),
+	}
+}
+
+// Get returns attachment id recorded for key.
+func (c *MemoryAttachmentCache) Get(ctx context.Context, key string) (string, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	attachmentID, ok := c.items[key]
+
+	return attachmentID, ok, nil
+}
+
+// Set records attachment id for key.
+func (c *MemoryAttachmentCache) Set(ctx context.Context, key string, attachmentID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.items[key] = attachmentID
+
+	return nil
+}
+
+// Len returns number of recorded attachments.
+func (c *MemoryAttachmentCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.items)
+}
+
+func validateAttachmentType(attachmentType MessageType) error {
+	switch attachmentType {
+	case MessageTypeImage, MessageTypeAudio, MessageTypeVideo, MessageTypeFile:
+		return nil
+	}
+
+	return ErrInvalidAttachmentType
+}
+
+// uploadAttachmentMessage defines message field of attachment upload requests,
+// uploaded attachments are always reusable.
+type uploadAttachmentMessage struct {
+	Attachment struct {
+		Type    MessageType `json:"type"`
+		Payload struct {
+			URL        string `json:"url,omitempty"`
+			IsReusable bool   `json:"is_reusable"`
+		} `json:"payload"`
+	} `json:"attachment"`
+}
+
+func newUploadAttachmentMessage(attachmentType MessageType, url string) *uploadAttachmentMessage {
+	m := &uploadAttachmentMessage{}
+	m.Attachment.Type = attachmentType
+	m.Attachment.Payload.URL = url
+	m.Attachment.Payload.IsReusable = true
+
+	return m
+}
+
+func encodeUploadAttachmentMultipart(w io.Writer, attachmentType MessageType, content []byte, filename string) (string, error) {
+	mw := multipart.NewWriter(w)
+
+	message, err := json.Marshal(newUploadAttachmentMessage(attachmentType, ""))
+	if err != nil {
+		return "", err
+	}
+
+	if err := mw.WriteField("message", string(message)); err != nil {
+		return "", err
+	}
+
+	contentType := mime.TypeByExtension(filepath.Ext(filename))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="filedata"; filename="%s"`, escapeQuotes(filename)))
+	h.Set("Content-Type", contentType)
+
+	part, err := mw.CreatePart(h)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := part.Write(content); err != nil {
+		return "", err
+	}
+
+	if err := mw.Close(); err != nil {
+		return "", err
+	}
+
+	return mw.FormDataContentType(), nil
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func escapeQuotes(s string) string {
+	return quoteEscaper.Replace(s)
+}
+
+// UploadAttachment uploads content of r as a reusable attachment of the given type,
+// one of image, audio, video or file, and returns its attachment id.
+// Content already uploaded by the client is not uploaded again,
+// its cached attachment id is returned instead.
+// https://developers.facebook.com/docs/messenger-platform/reference/attachment-upload-api
+func (c *Client) UploadAttachment(ctx context.Context, attachmentType MessageType, r io.Reader, filename string) (*UploadAttachmentResponse, error) {
+	if err := validateAttachmentType(attachmentType); err != nil {
+		return nil, err
+	}
+
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(content)
+	key := "content:" + string(attachmentType) + ":" + hex.EncodeToString(sum[:])
+
+	return c.uploadAttachment(ctx, key, func() (*UploadAttachmentResponse, error) {
+		var buf bytes.Buffer
+
+		contentType, err := encodeUploadAttachmentMultipart(&buf, attachmentType, content, filename)
+		if err != nil {
+			return nil, err
+		}
+
+		res, err := c.upload(ctx, APIEndpointMessageAttachments, &buf, contentType)
+		if err != nil {
+			return nil, err
+		}
+
+		defer res.Body.Close()
+
+		return decodeToUploadAttachmentResponse(res)
+	})
+}
+
+// UploadAttachmentFromURL uploads asset at url as a reusable attachment of the given type,
+// one of image, audio, video or file, and returns its attachment id.
+// An url already uploaded by the client is not uploaded again,
+// its cached attachment id is returned instead.
+// https://developers.facebook.com/docs/messenger-platform/reference/attachment-upload-api
+func (c *Client) UploadAttachmentFromURL(ctx context.Context, attachmentType MessageType, url string) (*UploadAttachmentResponse, error) {
+	if err := validateAttachmentType(attachmentType); err != nil {
+		return nil, err
+	}
+
+	key := "url:" + string(attachmentType) + ":" + url
+
+	return c.uploadAttachment(ctx, key, func() (*UploadAttachmentResponse, error) {
+		var buf bytes.Buffer
+
+		enc := json.NewEncoder(&buf)
+		if err := enc.Encode(&struct {
+			Message *uploadAttachmentMessage `json:"message"`
+		}{
+			Message: newUploadAttachmentMessage(attachmentType, url),
+		}); err != nil {
+			return nil, err
+		}
+
+		res, err := c.post(ctx, APIEndpointMessageAttachments, &buf)
+		if err != nil {
+			return nil, err
+		}
+
+		defer res.Body.Close()
+
+		return decodeToUploadAttachmentResponse(res)
+	})
+}
+
+// uploadAttachment returns attachment id cached for key,
+// or calls upload and caches the returned one.
+func (c *Client) uploadAttachment(ctx context.Context, key string, upload func() (*UploadAttachmentResponse, error)) (*UploadAttachmentResponse, error) {
+	attachmentID, ok, err := c.attachmentCache.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if ok {
+		return &UploadAttachmentResponse{AttachmentID: attachmentID}, nil
+	}
+
+	res, err := upload()
+	if err != nil {
+		return nil, err
+	}
+
+	if res.AttachmentID != "" {
+		if err := c.attachmentCache.Set(ctx, key, res.AttachmentID); err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,214 @@
+package instabot
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUploadAttachment(t *testing.T) {
+	pageAccessToken := "page_access_token"
+
+	var calls int32
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, APIEndpointMessageAttachments, r.URL.Path)
+		assert.Equal(t, pageAccessToken, r.URL.Query().Get("access_token"))
+
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatal(err)
+		}
+
+		assert.JSONEq(t, `{
+			"attachment": {
+				"type": "file",
+				"payload": {
+					"is_reusable": true
+				}
+			}
+		}`, r.FormValue("message"))
+
+		file, header, err := r.FormFile("filedata")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+
+		content, err := ioutil.ReadAll(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, "invoice.pdf", header.Filename)
+		assert.Equal(t, "application/pdf", header.Header.Get("Content-Type"))
+
+		w.WriteHeader(200)
+		w.Write([]byte(`{"attachment_id": "` + string(content) + `"}`))
+	}))
+	defer mockServer.Close()
+
+	client, err := New(pageAccessToken, WithEndpointBase(mockServer.URL))
+	assert.NoError(t, err)
+
+	res, err := client.UploadAttachment(context.Background(), MessageTypeFile, strings.NewReader("1000"), "invoice.pdf")
+	assert.NoError(t, err)
+	assert.Equal(t, &UploadAttachmentResponse{AttachmentID: "1000"}, res)
+
+	res, err = client.UploadAttachment(context.Background(), MessageTypeFile, strings.NewReader("1000"), "copy.pdf")
+	assert.NoError(t, err)
+	assert.Equal(t, &UploadAttachmentResponse{AttachmentID: "1000"}, res)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "same content must be uploaded once")
+
+	res, err = client.UploadAttachment(context.Background(), MessageTypeFile, strings.NewReader("2000"), "invoice.pdf")
+	assert.NoError(t, err)
+	assert.Equal(t, &UploadAttachmentResponse{AttachmentID: "2000"}, res)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestUploadAttachmentFromURL(t *testing.T) {
+	pageAccessToken := "page_access_token"
+
+	testCases := []struct {
+		name               string
+		attachmentType     MessageType
+		url                string
+		wantRequestBody    string
+		returnResponse     string
+		returnResponseCode int
+		want               *UploadAttachmentResponse
+		wantErr            error
+	}{
+		{
+			name:           "video upload",
+			attachmentType: MessageTypeVideo,
+			url:            "https://example.com/video.mp4",
+			wantRequestBody: `{
+				"message": {
+					"attachment": {
+						"type": "video",
+						"payload": {
+							"url": "https://example.com/video.mp4",
+							"is_reusable": true
+						}
+					}
+				}
+			}`,
+			returnResponse:     `{"attachment_id": "1857777774821032"}`,
+			returnResponseCode: 200,
+			want:               &UploadAttachmentResponse{AttachmentID: "1857777774821032"},
+		},
+		{
+			name:           "upload error",
+			attachmentType: MessageTypeImage,
+			url:            "https://example.com/image.png",
+			wantRequestBody: `{
+				"message": {
+					"attachment": {
+						"type": "image",
+						"payload": {
+							"url": "https://example.com/image.png",
+							"is_reusable": true
+						}
+					}
+				}
+			}`,
+			returnResponse: `{
+				"error": {
+					"message": "error",
+					"type": "OAuthException",
+					"code": 100,
+					"fbtrace_id": "fbtrace_id"
+				}
+			}`,
+			returnResponseCode: 400,
+			wantErr: &ErrorResponse{
+				StatusCode: 400,
+				APIError: APIError{
+					Message:   "error",
+					Type:      "OAuthException",
+					Code:      100,
+					FbTraceID: "fbtrace_id",
+				},
+			},
+		},
+		{
+			name:           "invalid attachment type",
+			attachmentType: MessageTypeSticker,
+			url:            "https://example.com/sticker.png",
+			wantErr:        ErrInvalidAttachmentType,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, APIEndpointMessageAttachments, r.URL.Path)
+
+				body, err := ioutil.ReadAll(r.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				assert.JSONEq(t, tc.wantRequestBody, string(body))
+
+				w.WriteHeader(tc.returnResponseCode)
+				w.Write([]byte(tc.returnResponse))
+			}))
+			defer mockServer.Close()
+
+			client, err := New(pageAccessToken, WithEndpointBase(mockServer.URL))
+			assert.NoError(t, err)
+
+			res, err := client.UploadAttachmentFromURL(context.Background(), tc.attachmentType, tc.url)
+			if tc.wantErr != nil {
+				assert.EqualError(t, err, tc.wantErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, tc.want, res)
+		})
+	}
+}
+
+func TestUploadAttachmentCache(t *testing.T) {
+	var calls int32
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+
+		w.WriteHeader(200)
+		json.NewEncoder(w).Encode(&UploadAttachmentResponse{AttachmentID: "1000"})
+	}))
+	defer mockServer.Close()
+
+	cache := NewMemoryAttachmentCache()
+
+	client, err := New("page_access_token", WithEndpointBase(mockServer.URL), WithAttachmentCache(cache))
+	assert.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		res, err := client.UploadAttachmentFromURL(context.Background(), MessageTypeAudio, "https://example.com/voice.mp3")
+		assert.NoError(t, err)
+		assert.Equal(t, "1000", res.AttachmentID)
+	}
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	assert.Equal(t, 1, cache.Len())
+
+	// same url of another type is a different attachment.
+	_, err = client.UploadAttachmentFromURL(context.Background(), MessageTypeFile, "https://example.com/voice.mp3")
+	assert.NoError(t, err)
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	assert.Equal(t, 2, cache.Len())
+}
@@ -11,8 +11,12 @@ import (
 // Client defines instabot.
 type Client struct {
 	pageAccessToken string
+	appID           string
+	appSecret       string
 	endpointBase    *url.URL
 	httpClient      *http.Client
+	attachmentCache AttachmentCache
+	privateReplies  DedupStore
 }
 
 // ClientOption defines optional argument for new client construction.
@@ -27,6 +31,39 @@ func WithHTTPClient(c *http.Client) ClientOption {
 	}
 }
 
+// WithAppCredentials sets app id and secret used as app access token
+// by app level apis, like app webhook subscriptions.
+func WithAppCredentials(appID string, appSecret string) ClientOption {
+	return func(client *Client) error {
+		client.appID = appID
+		client.appSecret = appSecret
+
+		return nil
+	}
+}
+
+// WithAttachmentCache sets cache of uploaded attachment ids,
+// defaults to an in memory cache.
+func WithAttachmentCache(cache AttachmentCache) ClientOption {
+	return func(client *Client) error {
+		client.attachmentCache = cache
+
+		return nil
+	}
+}
+
+// WithPrivateReplyStore sets store of comments already replied privately,
+// defaults to an in memory store remembering comments for PrivateReplyWindow.
+// Replicas sharing a store refuse second replies without calling the api,
+// otherwise instagram rejection is surfaced as the same PrivateReplyError.
+func WithPrivateReplyStore(store DedupStore) ClientOption {
+	return func(client *Client) error {
+		client.privateReplies = store
+
+		return nil
+	}
+}
+
 // WithEndpointBase sets client base endpoint.
 func WithEndpointBase(endpointBase string) ClientOption {
 	return func(client *Client) error {
@@ -71,6 +108,14 @@ func New(pageAccessToken string, options ...ClientOption) (*Client, error) {
 		client.httpClient = http.DefaultClient
 	}
 
+	if client.attachmentCache == nil {
+		client.attachmentCache = NewMemoryAttachmentCache()
+	}
+
+	if client.privateReplies == nil {
+		client.privateReplies = NewMemoryDedupStore(PrivateReplyWindow, 0)
+	}
+
 	return client, nil
 }
 
@@ -129,6 +174,23 @@ func (client *Client) post(ctx context.Context, endpoint string, body io.Reader)
 	return client.do(req)
 }
 
+// upload posts body of the given content type, like multipart form data.
+func (client *Client) upload(ctx context.Context, endpoint string, body io.Reader, contentType string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		client.url(client.endpointBase, endpoint),
+		body,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", contentType)
+
+	return client.do(req)
+}
+
 func (client *Client) put(ctx context.Context, endpoint string, body io.Reader) (*http.Response, error) {
 	req, err := http.NewRequestWithContext(
 		ctx,
@@ -166,3 +228,32 @@ func (client *Client) delete(ctx context.Context, endpoint string, body io.Reade
 
 	return client.do(req)
 }
+
+// appRequest sends request authenticated with app access token instead of page access token.
+func (client *Client) appRequest(ctx context.Context, method string, endpoint string, body io.Reader, query url.Values) (*http.Response, error) {
+	if client.appID == "" || client.appSecret == "" {
+		return nil, ErrMissingAppCredentials
+	}
+
+	u := *client.endpointBase
+	u.Path = path.Join(u.Path, endpoint)
+
+	if query == nil {
+		query = url.Values{}
+	}
+
+	query.Set("access_token", client.appID+"|"+client.appSecret)
+
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+	}
+
+	return client.do(req)
+}
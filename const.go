@@ -10,10 +10,22 @@ const (
 
 // instagram messaging api endpoints.
 var (
-	APIEndpointBase             = "https://graph.facebook.com"
-	APIEndpointSendMessage      = fmt.Sprintf("/%s/me/messages", APIVersion)
-	APIEndpointMessengerProfile = fmt.Sprintf("/%s/me/messenger_profile", APIVersion)
-	GetAPIEndpointUserProfile   = func(instagramUserID string) string {
+	APIEndpointBase                 = "https://graph.facebook.com"
+	APIEndpointSendMessage          = fmt.Sprintf("/%s/me/messages", APIVersion)
+	APIEndpointMessengerProfile     = fmt.Sprintf("/%s/me/messenger_profile", APIVersion)
+	APIEndpointMessageAttachments   = fmt.Sprintf("/%s/me/message_attachments", APIVersion)
+	APIEndpointPassThreadControl    = fmt.Sprintf("/%s/me/pass_thread_control", APIVersion)
+	APIEndpointTakeThreadControl    = fmt.Sprintf("/%s/me/take_thread_control", APIVersion)
+	APIEndpointRequestThreadControl = fmt.Sprintf("/%s/me/request_thread_control", APIVersion)
+	APIEndpointReleaseThreadControl = fmt.Sprintf("/%s/me/release_thread_control", APIVersion)
+	APIEndpointThreadOwner          = fmt.Sprintf("/%s/me/thread_owner", APIVersion)
+	GetAPIEndpointUserProfile       = func(instagramUserID string) string {
 		return fmt.Sprintf("/%s/%s", APIVersion, instagramUserID)
 	}
+	GetAPIEndpointSubscribedApps = func(pageID string) string {
+		return fmt.Sprintf("/%s/%s/subscribed_apps", APIVersion, pageID)
+	}
+	GetAPIEndpointAppSubscriptions = func(appID string) string {
+		return fmt.Sprintf("/%s/%s/subscriptions", APIVersion, appID)
+	}
 )
@@ -0,0 +1,257 @@
+package instabot
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// DedupStore records keys of handled events to detect redelivered ones.
+type DedupStore interface {
+	// MarkSeen records key and reports whether it was already recorded.
+	MarkSeen(ctx context.Context, key string) (bool, error)
+	// Forget removes key so a redelivery is handled again.
+	Forget(ctx context.Context, key string) error
+}
+
+// Dedup returns middleware dropping already handled events
+// before any following middleware or handler runs.
+// Events failed to be handled are forgotten so that their
+// redelivery is handled again.
+func Dedup(store DedupStore) Middleware {
+	return func(next MessagingHandlerFunc) MessagingHandlerFunc {
+		return func(ctx context.Context, m *Messaging) error {
+			key := m.DedupKey()
+
+			seen, err := store.MarkSeen(ctx, key)
+			if err != nil {
+				return err
+			}
+
+			if seen {
+				return nil
+			}
+
+			if err := next(ctx, m); err != nil {
+				if fErr := store.Forget(ctx, key); fErr != nil {
+					return &DispatchError{Errors: []error{err, fErr}}
+				}
+
+				return err
+			}
+
+			return nil
+		}
+	}
+}
+
+// DedupKey returns key identifying the event across redeliveries.
+// Message events are identified by event type and message id,
+// so that a message and its deletion are told apart, other events
+// by a hash of their payload and timestamp.
+func (m *Messaging) DedupKey() string {
+	if m.Message != nil && m.Message.MID != "" {
+		key := "mid:" + string(m.Type) + ":" + m.Message.MID
+		if m.Standby {
+			return "standby:" + key
+		}
+
+		return key
+	}
+
+	j, _ := json.Marshal(&struct {
+		Type      WebhookEventType `json:"type"`
+		Standby   bool             `json:"standby"`
+		Sender    *Sender          `json:"sender"`
+		Recipient *Recipient       `json:"recipient"`
+		Timestamp int64            `json:"timestamp"`
+		Read      *Read            `json:"read"`
+		Reaction  *Reaction        `json:"reaction"`
+		Referral  *Referral        `json:"referral"`
+		PostBack  *Postback        `json:"postback"`
+
+		PassThreadControl    *PassThreadControl    `json:"pass_thread_control"`
+		TakeThreadControl    *TakeThreadControl    `json:"take_thread_control"`
+		RequestThreadControl *RequestThreadControl `json:"request_thread_control"`
+	}{
+		Type:      m.Type,
+		Standby:   m.Standby,
+		Sender:    m.Sender,
+		Recipient: m.Recipient,
+		Timestamp: m.Timestamp,
+		Read:      m.Read,
+		Reaction:  m.Reaction,
+		Referral:  m.Referral,
+		PostBack:  m.PostBack,
+
+		PassThreadControl:    m.PassThreadControl,
+		TakeThreadControl:    m.TakeThreadControl,
+		RequestThreadControl: m.RequestThreadControl,
+	})
+
+	sum := sha256.Sum256(j)
+
+	return "hash:" + hex.EncodeToString(sum[:])
+}
+
+// DedupChange returns change middleware dropping already handled changes,
+// like Dedup does for messaging events. Add RecoverChange after it,
+// so that changes whose handler panics are forgotten too.
+func DedupChange(store DedupStore) ChangeMiddleware {
+	return func(next ChangeHandlerFunc) ChangeHandlerFunc {
+		return func(ctx context.Context, c *Change) error {
+			key := c.DedupKey()
+
+			seen, err := store.MarkSeen(ctx, key)
+			if err != nil {
+				return err
+			}
+
+			if seen {
+				return nil
+			}
+
+			if err := next(ctx, c); err != nil {
+				if fErr := store.Forget(ctx, key); fErr != nil {
+					return &DispatchError{Errors: []error{err, fErr}}
+				}
+
+				return err
+			}
+
+			return nil
+		}
+	}
+}
+
+// DedupKey returns key identifying the change across redeliveries.
+// Comments are identified by comment id, other changes
+// by a hash of their account, field and value.
+func (c *Change) DedupKey() string {
+	if c.Comment != nil && c.Comment.ID != "" {
+		return "comment:" + c.Field + ":" + c.Comment.ID
+	}
+
+	h := sha256.New()
+	h.Write([]byte(c.accountID))
+	h.Write([]byte{0})
+	h.Write([]byte(c.Field))
+	h.Write([]byte{0})
+	h.Write(c.Value)
+
+	return "change:" + hex.EncodeToString(h.Sum(nil))
+}
+
+type dedupEntry struct {
+	key       string
+	expiresAt time.Time
+}
+
+// MemoryDedupStore defines in memory DedupStore
+// evicting keys after ttl or when capacity is exceeded,
+// least recently seen first.
+type MemoryDedupStore struct {
+	ttl      time.Duration
+	capacity int
+	now      func() time.Time
+
+	mu    sync.Mutex
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+// compile time interface implementation check.
+var _ DedupStore = (*MemoryDedupStore)(nil)
+
+// NewMemoryDedupStore returns a new in memory dedup store.
+// Keys never expire when ttl is not positive and
+// the store is unbounded when capacity is not positive.
+func NewMemoryDedupStore(ttl time.Duration, capacity int) *MemoryDedupStore {
+	return &MemoryDedupStore{
+		ttl:      ttl,
+		capacity: capacity,
+		now:      time.Now,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+// MarkSeen records key and reports whether it was already recorded.
+func (s *MemoryDedupStore) MarkSeen(ctx context.Context, key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+
+	s.purgeExpired(now)
+
+	if el, ok := s.items[key]; ok {
+		entry := el.Value.(*dedupEntry)
+		if s.ttl <= 0 || now.Before(entry.expiresAt) {
+			s.ll.MoveToFront(el)
+
+			return true, nil
+		}
+
+		s.remove(el)
+	}
+
+	s.items[key] = s.ll.PushFront(&dedupEntry{
+		key:       key,
+		expiresAt: now.Add(s.ttl),
+	})
+
+	if s.capacity > 0 {
+		for s.ll.Len() > s.capacity {
+			s.remove(s.ll.Back())
+		}
+	}
+
+	return false, nil
+}
+
+// Forget removes key from the store.
+func (s *MemoryDedupStore) Forget(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if el, ok := s.items[key]; ok {
+		s.remove(el)
+	}
+
+	return nil
+}
+
+// Len returns number of recorded keys, including expired ones not yet evicted.
+func (s *MemoryDedupStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.ll.Len()
+}
+
+// purgeExpired removes expired keys from the back of the list.
+// Keys share the same ttl, so keys behind the first unexpired one
+// are only kept until it expires too.
+func (s *MemoryDedupStore) purgeExpired(now time.Time) {
+	if s.ttl <= 0 {
+		return
+	}
+
+	for el := s.ll.Back(); el != nil; el = s.ll.Back() {
+		if now.Before(el.Value.(*dedupEntry).expiresAt) {
+			return
+		}
+
+		s.remove(el)
+	}
+}
+
+func (s *MemoryDedupStore) remove(el *list.Element) {
+	s.ll.Remove(el)
+	delete(s.items, el.Value.(*dedupEntry).key)
+}
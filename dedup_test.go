@@ -0,0 +1,293 @@
+package instabot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessagingDedupKey(t *testing.T) {
+	testCases := []struct {
+		name      string
+		a         *Messaging
+		b         *Messaging
+		wantEqual bool
+	}{
+		{
+			name: "message events with same mid",
+			a: &Messaging{
+				Sender:    &Sender{ID: "<IGSID>"},
+				Timestamp: 1,
+				Message:   &WebhookMessage{MID: "<MID>", Text: "a"},
+			},
+			b: &Messaging{
+				Sender:    &Sender{ID: "<IGSID>"},
+				Timestamp: 2,
+				Message:   &WebhookMessage{MID: "<MID>", Text: "a"},
+			},
+			wantEqual: true,
+		},
+		{
+			name: "message events with different mid",
+			a: &Messaging{
+				Message: &WebhookMessage{MID: "<MID_1>"},
+			},
+			b: &Messaging{
+				Message: &WebhookMessage{MID: "<MID_2>"},
+			},
+			wantEqual: false,
+		},
+		{
+			name: "message and its deletion",
+			a: &Messaging{
+				Type:    WebhookEventTypeTextMessage,
+				Message: &WebhookMessage{MID: "<MID>", Text: "a"},
+			},
+			b: &Messaging{
+				Type:    WebhookEventTypeDeleted,
+				Message: &WebhookMessage{MID: "<MID>", IsDeleted: true},
+			},
+			wantEqual: false,
+		},
+		{
+			name: "same reaction events",
+			a: &Messaging{
+				Type:      WebhookEventTypeReaction,
+				Sender:    &Sender{ID: "<IGSID>"},
+				Timestamp: 1,
+				Reaction:  &Reaction{MID: "<MID>", Action: "react", Reaction: "love"},
+			},
+			b: &Messaging{
+				Type:      WebhookEventTypeReaction,
+				Sender:    &Sender{ID: "<IGSID>"},
+				Timestamp: 1,
+				Reaction:  &Reaction{MID: "<MID>", Action: "react", Reaction: "love"},
+			},
+			wantEqual: true,
+		},
+		{
+			name: "react and unreact events",
+			a: &Messaging{
+				Type:      WebhookEventTypeReaction,
+				Sender:    &Sender{ID: "<IGSID>"},
+				Timestamp: 1,
+				Reaction:  &Reaction{MID: "<MID>", Action: "react", Reaction: "love"},
+			},
+			b: &Messaging{
+				Type:      WebhookEventTypeReaction,
+				Sender:    &Sender{ID: "<IGSID>"},
+				Timestamp: 2,
+				Reaction:  &Reaction{MID: "<MID>", Action: "unreact"},
+			},
+			wantEqual: false,
+		},
+		{
+			name: "same postback at different time",
+			a: &Messaging{
+				Type:      WebhookEventTypePostBack,
+				Timestamp: 1,
+				PostBack:  &Postback{Payload: "<PAYLOAD>"},
+			},
+			b: &Messaging{
+				Type:      WebhookEventTypePostBack,
+				Timestamp: 2,
+				PostBack:  &Postback{Payload: "<PAYLOAD>"},
+			},
+			wantEqual: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.wantEqual, tc.a.DedupKey() == tc.b.DedupKey())
+		})
+	}
+}
+
+func TestMemoryDedupStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(0, 0)
+
+	s := NewMemoryDedupStore(time.Minute, 2)
+	s.now = func() time.Time { return now }
+
+	seen, err := s.MarkSeen(ctx, "a")
+	assert.NoError(t, err)
+	assert.False(t, seen)
+
+	seen, _ = s.MarkSeen(ctx, "a")
+	assert.True(t, seen)
+
+	// capacity eviction, least recently seen first.
+	s.MarkSeen(ctx, "b")
+	s.MarkSeen(ctx, "a")
+	s.MarkSeen(ctx, "c")
+	assert.Equal(t, 2, s.Len())
+
+	seen, _ = s.MarkSeen(ctx, "a")
+	assert.True(t, seen)
+
+	seen, _ = s.MarkSeen(ctx, "b")
+	assert.False(t, seen)
+
+	// ttl expiry.
+	now = now.Add(2 * time.Minute)
+
+	seen, _ = s.MarkSeen(ctx, "b")
+	assert.False(t, seen)
+
+	// forget.
+	assert.NoError(t, s.Forget(ctx, "b"))
+
+	seen, _ = s.MarkSeen(ctx, "b")
+	assert.False(t, seen)
+}
+
+func TestMemoryDedupStorePurgesExpired(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(0, 0)
+
+	s := NewMemoryDedupStore(time.Millisecond, 0)
+	s.now = func() time.Time { return now }
+
+	for i := 0; i < 1000; i++ {
+		s.MarkSeen(ctx, strconv.Itoa(i))
+	}
+	assert.Equal(t, 1000, s.Len())
+
+	now = now.Add(time.Second)
+
+	seen, err := s.MarkSeen(ctx, "new")
+	assert.NoError(t, err)
+	assert.False(t, seen)
+	assert.Equal(t, 1, s.Len())
+}
+
+func TestDedupMiddleware(t *testing.T) {
+	payload := `{
+		"object": "instagram",
+		"entry": [
+		  {
+			"id": "<IGID>",
+			"time": 1569262486134,
+			"messaging": [
+			  {
+				"sender": {
+				  "id": "<IGSID>"
+				},
+				"recipient": {
+				  "id": "<IGID>"
+				},
+				"timestamp": 1569262485349,
+				"message": {
+				  "mid": "<MESSAGE_ID>",
+				  "text": "<MESSAGE_CONTENT>"
+				}
+			  }
+			]
+		  }
+		]
+	}`
+
+	errHandler := errors.New("handler error")
+	failures := 1
+	called := 0
+
+	d := NewDispatcher()
+	d.Use(Dedup(NewMemoryDedupStore(time.Hour, 100)))
+	d.OnTextMessage(func(ctx context.Context, event *TextMessageEvent) error {
+		called++
+
+		if failures > 0 {
+			failures--
+
+			return errHandler
+		}
+
+		return nil
+	})
+
+	deliver := func() error {
+		e := new(WebhookEvent)
+		assert.NoError(t, json.Unmarshal([]byte(payload), e))
+
+		return d.HandleWebhookEvent(context.Background(), e)
+	}
+
+	// failed delivery is forgotten and handled again on redelivery.
+	assert.True(t, errors.Is(deliver(), errHandler))
+	assert.NoError(t, deliver())
+	assert.NoError(t, deliver())
+	assert.NoError(t, deliver())
+
+	assert.Equal(t, 2, called)
+}
+
+func TestDedupMessageDeletion(t *testing.T) {
+	payload := `{
+		"object": "instagram",
+		"entry": [
+		  {
+			"id": "<IGID>",
+			"time": 1569262486134,
+			"messaging": [
+			  {
+				"sender": {
+				  "id": "<IGSID>"
+				},
+				"recipient": {
+				  "id": "<IGID>"
+				},
+				"timestamp": 1569262485349,
+				"message": {
+				  "mid": "<MESSAGE_ID>",
+				  "text": "<MESSAGE_CONTENT>"
+				}
+			  },
+			  {
+				"sender": {
+				  "id": "<IGSID>"
+				},
+				"recipient": {
+				  "id": "<IGID>"
+				},
+				"timestamp": 1569262495349,
+				"message": {
+				  "mid": "<MESSAGE_ID>",
+				  "is_deleted": true
+				}
+			  }
+			]
+		  }
+		]
+	}`
+
+	var texts, deletes int
+
+	d := NewDispatcher()
+	d.Use(Dedup(NewMemoryDedupStore(time.Hour, 100)))
+	d.OnTextMessage(func(ctx context.Context, event *TextMessageEvent) error {
+		texts++
+
+		return nil
+	})
+	d.OnMessageDelete(func(ctx context.Context, event *MessageDeleteEvent) error {
+		deletes++
+
+		return nil
+	})
+
+	for i := 0; i < 2; i++ {
+		e := new(WebhookEvent)
+		assert.NoError(t, json.Unmarshal([]byte(payload), e))
+		assert.NoError(t, d.HandleWebhookEvent(context.Background(), e))
+	}
+
+	assert.Equal(t, 1, texts)
+	assert.Equal(t, 1, deletes)
+}
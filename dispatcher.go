@@ -0,0 +1,324 @@
+package instabot
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// MessagingHandlerFunc handles a single messaging event.
+type MessagingHandlerFunc func(ctx context.Context, m *Messaging) error
+
+// ChangeHandlerFunc handles a single feed change event.
+type ChangeHandlerFunc func(ctx context.Context, c *Change) error
+
+// DispatchError holds every error returned by handlers
+// while dispatching a webhook event.
+type DispatchError struct {
+	Errors []error
+}
+
+// Error returns error message.
+func (e *DispatchError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		messages = append(messages, err.Error())
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// Is reports whether any of the dispatch errors matches target.
+func (e *DispatchError) Is(target error) bool {
+	for _, err := range e.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Dispatcher routes webhook messaging and feed change events
+// to typed handlers registered per webhook event type.
+type Dispatcher struct {
+	handlers       map[WebhookEventType]MessagingHandlerFunc
+	changeHandlers map[WebhookEventType]ChangeHandlerFunc
+	fallback       MessagingHandlerFunc
+	standby        MessagingHandlerFunc
+	middlewares    []Middleware
+
+	changeMiddlewares []ChangeMiddleware
+}
+
+// compile time interface implementation check.
+var _ WebhookEventHandler = (*Dispatcher)(nil)
+
+// NewDispatcher returns a new dispatcher without any handler.
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{
+		handlers:       make(map[WebhookEventType]MessagingHandlerFunc),
+		changeHandlers: make(map[WebhookEventType]ChangeHandlerFunc),
+	}
+}
+
+// Handle registers handler for the given webhook event type,
+// replacing previously registered one.
+func (d *Dispatcher) Handle(eventType WebhookEventType, handler MessagingHandlerFunc) {
+	d.handlers[eventType] = handler
+}
+
+// OnTextMessage registers text message event handler.
+func (d *Dispatcher) OnTextMessage(handler func(ctx context.Context, event *TextMessageEvent) error) {
+	d.Handle(WebhookEventTypeTextMessage, func(ctx context.Context, m *Messaging) error {
+		return handler(ctx, m.GetTextMessageEvent())
+	})
+}
+
+// OnMediaMessage registers media (image, audio, video, file) message event handler.
+func (d *Dispatcher) OnMediaMessage(handler func(ctx context.Context, event *MediaMessageEvent) error) {
+	h := func(ctx context.Context, m *Messaging) error {
+		return handler(ctx, m.GetMediaMessageEvent())
+	}
+
+	d.Handle(WebhookEventTypeImageMessage, h)
+	d.Handle(WebhookEventTypeAudioMessage, h)
+	d.Handle(WebhookEventTypeVideoMessage, h)
+	d.Handle(WebhookEventTypeFileMessage, h)
+}
+
+// OnQuickReply registers quick reply event handler.
+func (d *Dispatcher) OnQuickReply(handler func(ctx context.Context, event *QuickReplyEvent) error) {
+	d.Handle(WebhookEventTypeQuickReply, func(ctx context.Context, m *Messaging) error {
+		return handler(ctx, m.GetQuickReplyEvent())
+	})
+}
+
+// OnPostBack registers postback event handler.
+func (d *Dispatcher) OnPostBack(handler func(ctx context.Context, event *PostBackEvent) error) {
+	d.Handle(WebhookEventTypePostBack, func(ctx context.Context, m *Messaging) error {
+		return handler(ctx, m.GetPostBackEvent())
+	})
+}
+
+// OnStoryMention registers story mention event handler.
+func (d *Dispatcher) OnStoryMention(handler func(ctx context.Context, event *StoryMentionEvent) error) {
+	d.Handle(WebhookEventTypeStoryMention, func(ctx context.Context, m *Messaging) error {
+		return handler(ctx, m.GetStoryMentionEvent())
+	})
+}
+
+// OnStoryReply registers story reply event handler.
+func (d *Dispatcher) OnStoryReply(handler func(ctx context.Context, event *StoryReplyEvent) error) {
+	d.Handle(WebhookEventTypeStoryReply, func(ctx context.Context, m *Messaging) error {
+		return handler(ctx, m.GetStoryReplyEvent())
+	})
+}
+
+// OnMessageReply registers message reply event handler.
+func (d *Dispatcher) OnMessageReply(handler func(ctx context.Context, event *MessageReplyEvent) error) {
+	d.Handle(WebhookEventTypeMessageReply, func(ctx context.Context, m *Messaging) error {
+		return handler(ctx, m.GetMessageReplyEvent())
+	})
+}
+
+// OnShare registers message share (share, ig reel, reel, ig post,
+// story, fallback, template) event handler.
+func (d *Dispatcher) OnShare(handler func(ctx context.Context, event *MessageShareEvent) error) {
+	h := func(ctx context.Context, m *Messaging) error {
+		return handler(ctx, m.GetMessageShareEvent())
+	}
+
+	d.Handle(WebhookEventTypeShare, h)
+	d.Handle(WebhookEventTypeIGReel, h)
+	d.Handle(WebhookEventTypeReel, h)
+	d.Handle(WebhookEventTypeIGPost, h)
+	d.Handle(WebhookEventTypeStory, h)
+	d.Handle(WebhookEventTypeFallback, h)
+	d.Handle(WebhookEventTypeTemplate, h)
+}
+
+// OnReaction registers message reaction event handler.
+func (d *Dispatcher) OnReaction(handler func(ctx context.Context, event *MessageReactionEvent) error) {
+	d.Handle(WebhookEventTypeReaction, func(ctx context.Context, m *Messaging) error {
+		return handler(ctx, m.GetMessageReactionEvent())
+	})
+}
+
+// OnMessageSeen registers message seen event handler.
+func (d *Dispatcher) OnMessageSeen(handler func(ctx context.Context, event *MessageSeenEvent) error) {
+	d.Handle(WebhookEventTypeMessageSeen, func(ctx context.Context, m *Messaging) error {
+		return handler(ctx, m.GetMessageSeenEvent())
+	})
+}
+
+// OnReferral registers referral event handler.
+// Referrals nested inside message or postback events are
+// delivered to the handler of those events, see Messaging.HasReferral.
+func (d *Dispatcher) OnReferral(handler func(ctx context.Context, event *ReferralEvent) error) {
+	d.Handle(WebhookEventTypeReferral, func(ctx context.Context, m *Messaging) error {
+		return handler(ctx, m.GetReferralEvent())
+	})
+}
+
+// OnMessageDelete registers message delete event handler.
+func (d *Dispatcher) OnMessageDelete(handler func(ctx context.Context, event *MessageDeleteEvent) error) {
+	d.Handle(WebhookEventTypeDeleted, func(ctx context.Context, m *Messaging) error {
+		return handler(ctx, m.GetMessageDeleteEvent())
+	})
+}
+
+// OnEcho registers echo event handler, called for every message
+// sent by the instagram account.
+func (d *Dispatcher) OnEcho(handler func(ctx context.Context, event *EchoEvent) error) {
+	d.Handle(WebhookEventTypeEcho, func(ctx context.Context, m *Messaging) error {
+		return handler(ctx, m.GetEchoEvent())
+	})
+}
+
+// OnPassThreadControl registers pass thread control event handler.
+func (d *Dispatcher) OnPassThreadControl(handler func(ctx context.Context, event *PassThreadControlEvent) error) {
+	d.Handle(WebhookEventTypePassThreadControl, func(ctx context.Context, m *Messaging) error {
+		return handler(ctx, m.GetPassThreadControlEvent())
+	})
+}
+
+// OnTakeThreadControl registers take thread control event handler.
+func (d *Dispatcher) OnTakeThreadControl(handler func(ctx context.Context, event *TakeThreadControlEvent) error) {
+	d.Handle(WebhookEventTypeTakeThreadControl, func(ctx context.Context, m *Messaging) error {
+		return handler(ctx, m.GetTakeThreadControlEvent())
+	})
+}
+
+// OnRequestThreadControl registers request thread control event handler.
+func (d *Dispatcher) OnRequestThreadControl(handler func(ctx context.Context, event *RequestThreadControlEvent) error) {
+	d.Handle(WebhookEventTypeRequestThreadControl, func(ctx context.Context, m *Messaging) error {
+		return handler(ctx, m.GetRequestThreadControlEvent())
+	})
+}
+
+// OnStandby registers handler for standby events, received while
+// another app owns the conversation. Standby events are never passed
+// to typed or fallback handlers, they are ignored without a standby handler.
+func (d *Dispatcher) OnStandby(handler MessagingHandlerFunc) {
+	d.standby = handler
+}
+
+// OnUnsupported registers handler for messages instagram marks as unsupported.
+func (d *Dispatcher) OnUnsupported(handler MessagingHandlerFunc) {
+	d.Handle(WebhookEventTypeUnsupported, handler)
+}
+
+// OnChange registers feed change handler for the given webhook event type,
+// replacing previously registered one.
+func (d *Dispatcher) OnChange(eventType WebhookEventType, handler ChangeHandlerFunc) {
+	d.changeHandlers[eventType] = handler
+}
+
+// OnComment registers comment event handler.
+func (d *Dispatcher) OnComment(handler func(ctx context.Context, event *CommentEvent) error) {
+	d.OnChange(WebhookEventTypeComment, func(ctx context.Context, c *Change) error {
+		return handler(ctx, c.GetCommentEvent())
+	})
+}
+
+// OnLiveComment registers live comment event handler.
+func (d *Dispatcher) OnLiveComment(handler func(ctx context.Context, event *CommentEvent) error) {
+	d.OnChange(WebhookEventTypeLiveComment, func(ctx context.Context, c *Change) error {
+		return handler(ctx, c.GetCommentEvent())
+	})
+}
+
+// OnMention registers mention event handler.
+func (d *Dispatcher) OnMention(handler func(ctx context.Context, event *MentionEvent) error) {
+	d.OnChange(WebhookEventTypeMention, func(ctx context.Context, c *Change) error {
+		return handler(ctx, c.GetMentionEvent())
+	})
+}
+
+// OnStoryInsights registers story insights event handler.
+func (d *Dispatcher) OnStoryInsights(handler func(ctx context.Context, event *StoryInsightsEvent) error) {
+	d.OnChange(WebhookEventTypeStoryInsights, func(ctx context.Context, c *Change) error {
+		return handler(ctx, c.GetStoryInsightsEvent())
+	})
+}
+
+// OnFallback registers handler for messaging events without any registered handler.
+func (d *Dispatcher) OnFallback(handler MessagingHandlerFunc) {
+	d.fallback = handler
+}
+
+// HandleWebhookEvent dispatches every messaging, standby and change of every entry
+// to its handler, errors of all handlers are collected into a DispatchError.
+func (d *Dispatcher) HandleWebhookEvent(ctx context.Context, event *WebhookEvent) error {
+	var errs []error
+
+	for _, entry := range event.Entries {
+		for _, m := range entry.Messaging {
+			if err := d.HandleMessaging(ctx, m); err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		for _, m := range entry.Standby {
+			if err := d.HandleMessaging(ctx, m); err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		for _, c := range entry.Changes {
+			if err := d.HandleChange(ctx, c); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return &DispatchError{Errors: errs}
+	}
+
+	return nil
+}
+
+// HandleMessaging dispatches a single messaging event through
+// the middlewares to its handler.
+// Events without registered handler are passed to the fallback handler,
+// or ignored when there is none.
+func (d *Dispatcher) HandleMessaging(ctx context.Context, m *Messaging) error {
+	return chainMiddlewares(d.route, d.middlewares)(ctx, m)
+}
+
+func (d *Dispatcher) route(ctx context.Context, m *Messaging) error {
+	if m.Standby {
+		if d.standby != nil {
+			return d.standby(ctx, m)
+		}
+
+		return nil
+	}
+
+	if handler, ok := d.handlers[m.Type]; ok {
+		return handler(ctx, m)
+	}
+
+	if d.fallback != nil {
+		return d.fallback(ctx, m)
+	}
+
+	return nil
+}
+
+// HandleChange dispatches a single feed change event through
+// the change middlewares to its handler.
+// Changes without registered handler are ignored.
+func (d *Dispatcher) HandleChange(ctx context.Context, c *Change) error {
+	return chainChangeMiddlewares(d.routeChange, d.changeMiddlewares)(ctx, c)
+}
+
+func (d *Dispatcher) routeChange(ctx context.Context, c *Change) error {
+	if handler, ok := d.changeHandlers[c.Type]; ok {
+		return handler(ctx, c)
+	}
+
+	return nil
+}
@@ -0,0 +1,217 @@
+package instabot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const dispatcherTestPayload = `{
+	"object": "instagram",
+	"entry": [
+	  {
+		"id": "<IGID>",
+		"time": 1569262486134,
+		"messaging": [
+		  {
+			"sender": {
+			  "id": "<IGSID>"
+			},
+			"recipient": {
+			  "id": "<IGID>"
+			},
+			"timestamp": 1569262485349,
+			"message": {
+			  "mid": "<MESSAGE_ID>",
+			  "text": "<MESSAGE_CONTENT>"
+			}
+		  },
+		  {
+			"sender": {
+			  "id": "<IGSID>"
+			},
+			"recipient": {
+			  "id": "<IGID>"
+			},
+			"timestamp": 1569262485349,
+			"postback": {
+			  "mid": "<MESSAGE_ID>",
+			  "title": "<TITLE>",
+			  "payload": "<PAYLOAD>"
+			}
+		  }
+		]
+	  },
+	  {
+		"id": "<IGID>",
+		"time": 1569262486134,
+		"messaging": [
+		  {
+			"sender": {
+			  "id": "<IGSID>"
+			},
+			"recipient": {
+			  "id": "<IGID>"
+			},
+			"timestamp": 1569262485349,
+			"read": {
+			  "mid": "<MESSAGE_ID>"
+			}
+		  },
+		  {
+			"sender": {
+			  "id": "<IGSID>"
+			},
+			"recipient": {
+			  "id": "<IGID>"
+			},
+			"timestamp": 1569262485349,
+			"message": {
+			  "mid": "<MESSAGE_ID>",
+			  "is_unsupported": true
+			}
+		  }
+		]
+	  }
+	]
+}`
+
+func TestDispatcher(t *testing.T) {
+	errHandler := errors.New("handler error")
+
+	testCases := []struct {
+		name     string
+		register func(d *Dispatcher, called map[WebhookEventType]int)
+		wantCall map[WebhookEventType]int
+		wantErrs []error
+	}{
+		{
+			name: "it should call typed handler of every messaging",
+			register: func(d *Dispatcher, called map[WebhookEventType]int) {
+				d.OnTextMessage(func(ctx context.Context, event *TextMessageEvent) error {
+					assert.Equal(t, "<MESSAGE_CONTENT>", event.Text)
+					called[WebhookEventTypeTextMessage]++
+
+					return nil
+				})
+				d.OnPostBack(func(ctx context.Context, event *PostBackEvent) error {
+					assert.Equal(t, "<PAYLOAD>", event.Data.Payload)
+					called[WebhookEventTypePostBack]++
+
+					return nil
+				})
+				d.OnMessageSeen(func(ctx context.Context, event *MessageSeenEvent) error {
+					assert.Equal(t, "<MESSAGE_ID>", event.SeenMID)
+					called[WebhookEventTypeMessageSeen]++
+
+					return nil
+				})
+				d.OnUnsupported(func(ctx context.Context, m *Messaging) error {
+					called[WebhookEventTypeUnsupported]++
+
+					return nil
+				})
+			},
+			wantCall: map[WebhookEventType]int{
+				WebhookEventTypeTextMessage: 1,
+				WebhookEventTypePostBack:    1,
+				WebhookEventTypeMessageSeen: 1,
+				WebhookEventTypeUnsupported: 1,
+			},
+		},
+		{
+			name: "it should call fallback, when event type has no handler",
+			register: func(d *Dispatcher, called map[WebhookEventType]int) {
+				d.OnTextMessage(func(ctx context.Context, event *TextMessageEvent) error {
+					called[WebhookEventTypeTextMessage]++
+
+					return nil
+				})
+				d.OnFallback(func(ctx context.Context, m *Messaging) error {
+					called[m.Type]++
+
+					return nil
+				})
+			},
+			wantCall: map[WebhookEventType]int{
+				WebhookEventTypeTextMessage: 1,
+				WebhookEventTypePostBack:    1,
+				WebhookEventTypeMessageSeen: 1,
+				WebhookEventTypeUnsupported: 1,
+			},
+		},
+		{
+			name: "it should aggregate errors of all handlers",
+			register: func(d *Dispatcher, called map[WebhookEventType]int) {
+				d.OnTextMessage(func(ctx context.Context, event *TextMessageEvent) error {
+					called[WebhookEventTypeTextMessage]++
+
+					return errHandler
+				})
+				d.OnMessageSeen(func(ctx context.Context, event *MessageSeenEvent) error {
+					called[WebhookEventTypeMessageSeen]++
+
+					return errHandler
+				})
+			},
+			wantCall: map[WebhookEventType]int{
+				WebhookEventTypeTextMessage: 1,
+				WebhookEventTypeMessageSeen: 1,
+			},
+			wantErrs: []error{errHandler, errHandler},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := new(WebhookEvent)
+			err := json.Unmarshal([]byte(dispatcherTestPayload), e)
+			assert.NoError(t, err)
+
+			called := map[WebhookEventType]int{}
+			d := NewDispatcher()
+			tc.register(d, called)
+
+			err = d.HandleWebhookEvent(context.Background(), e)
+			if tc.wantErrs != nil {
+				dErr := &DispatchError{}
+				assert.True(t, errors.As(err, &dErr))
+				assert.Equal(t, tc.wantErrs, dErr.Errors)
+				assert.True(t, errors.Is(err, errHandler))
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, tc.wantCall, called)
+		})
+	}
+}
+
+func TestDispatcherOnShareTemplate(t *testing.T) {
+	m := &Messaging{
+		Sender:    &Sender{ID: "<IGSID>"},
+		Recipient: &Recipient{ID: "<IGID>"},
+		Message: &WebhookMessage{
+			MID:         "<MESSAGE_ID>",
+			Attachments: []*Attachment{{Type: "template"}},
+		},
+	}
+	m.setType()
+
+	var got *MessageShareEvent
+
+	d := NewDispatcher()
+	d.OnShare(func(ctx context.Context, event *MessageShareEvent) error {
+		got = event
+
+		return nil
+	})
+
+	assert.NoError(t, d.HandleMessaging(context.Background(), m))
+	assert.NotNil(t, got)
+	assert.Equal(t, WebhookEventTypeTemplate, got.Type)
+	assert.Equal(t, "<MESSAGE_ID>", got.MID)
+}
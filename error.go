@@ -6,4 +6,82 @@ var (
 	// ErrMissingPageAccessToken happens when instantiating instabot
 	// with empty page access token.
 	ErrMissingPageAccessToken = errors.New("missing page access token")
+
+	// ErrMissingAppCredentials happens when calling app level apis
+	// on a client instantiated without app id and secret.
+	ErrMissingAppCredentials = errors.New("missing app credentials")
+
+	// ErrMissingAppSecret happens when instantiating webhook handler
+	// with empty app secret.
+	ErrMissingAppSecret = errors.New("missing app secret")
+
+	// ErrMissingWebhookEventHandler happens when instantiating webhook handler
+	// without a webhook event handler.
+	ErrMissingWebhookEventHandler = errors.New("missing webhook event handler")
+
+	// ErrInvalidMaxBodySize happens when webhook handler max body size
+	// is not positive.
+	ErrInvalidMaxBodySize = errors.New("invalid max body size")
+
+	// ErrMissingVerifyToken happens when webhook handler verify token
+	// or verifier is empty.
+	ErrMissingVerifyToken = errors.New("missing verify token")
+
+	// ErrHandlerPanic happens when a webhook event handler panics
+	// and the panic is recovered by Recover middleware.
+	ErrHandlerPanic = errors.New("handler panic")
+
+	// ErrInvalidWorkers happens when worker pool number of workers
+	// is not positive.
+	ErrInvalidWorkers = errors.New("invalid number of workers")
+
+	// ErrInvalidQueueSize happens when worker pool queue size
+	// is not positive.
+	ErrInvalidQueueSize = errors.New("invalid queue size")
+
+	// ErrQueueFull happens when worker pool queue can not accept more events.
+	ErrQueueFull = errors.New("queue is full")
+
+	// ErrWorkerPoolClosed happens when queuing events after
+	// worker pool shutdown.
+	ErrWorkerPoolClosed = errors.New("worker pool is closed")
+
+	// ErrMissingSignature happens when webhook payload is not signed.
+	ErrMissingSignature = errors.New("missing signature")
+
+	// ErrInvalidSignature happens when webhook payload signature
+	// does not match.
+	ErrInvalidSignature = errors.New("invalid signature")
+
+	// ErrUnknownWebhookField happens when strictly decoding a webhook
+	// event holding a field unknown to instabot.
+	ErrUnknownWebhookField = errors.New("unknown webhook field")
+
+	// ErrUnclassifiedWebhookEvent happens when strictly decoding a webhook
+	// event none of the webhook event types matches.
+	ErrUnclassifiedWebhookEvent = errors.New("unclassified webhook event")
+
+	// ErrInvalidMessagingType happens when sending a tagged message
+	// with messaging type other than MessagingTypeMessageTag.
+	ErrInvalidMessagingType = errors.New("invalid messaging type")
+
+	// ErrMissingMessageTag happens when sending a message with
+	// MessagingTypeMessageTag messaging type without a tag.
+	ErrMissingMessageTag = errors.New("missing message tag")
+
+	// ErrInvalidAttachmentType happens when uploading or sending by attachment id
+	// an attachment other than image, audio, video or file.
+	ErrInvalidAttachmentType = errors.New("invalid attachment type")
+
+	// ErrCommentAlreadyReplied happens when sending a second
+	// private reply to the same comment.
+	ErrCommentAlreadyReplied = errors.New("comment already replied")
+
+	// ErrPrivateReplyWindowExpired happens when sending a private reply
+	// to a comment older than PrivateReplyWindow.
+	ErrPrivateReplyWindowExpired = errors.New("private reply window expired")
+
+	// ErrCommentCannotBeReplied happens when instagram refuses a private
+	// reply to a comment for a reason other than the two rules above.
+	ErrCommentCannotBeReplied = errors.New("comment cannot be replied")
 )
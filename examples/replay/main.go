@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"github.com/BackAged/instabot"
+)
+
+func main() {
+	// usage: go run ./examples/replay deliveries.jsonl
+	// deliveries.jsonl is recorded by a webhook handler
+	// instantiated with instabot.WithRecorder.
+	if len(os.Args) != 2 {
+		log.Fatal("usage: replay <recorded_deliveries.jsonl>")
+	}
+
+	// registering the same handlers used by the webhook handler.
+	dispatcher := instabot.NewDispatcher()
+
+	dispatcher.Use(instabot.Recover(), instabot.Logger(nil))
+	dispatcher.UseChange(instabot.RecoverChange(), instabot.LoggerChange(nil))
+
+	dispatcher.OnTextMessage(func(ctx context.Context, event *instabot.TextMessageEvent) error {
+		log.Println(event)
+
+		return nil
+	})
+
+	dispatcher.OnFallback(func(ctx context.Context, m *instabot.Messaging) error {
+		log.Println("unhandled event", m.Type)
+
+		return nil
+	})
+
+	if err := instabot.ReplayFile(context.Background(), os.Args[1], dispatcher); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -1,8 +1,9 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
 	"log"
+	"net/http"
 
 	"github.com/BackAged/instabot"
 )
@@ -10,73 +11,103 @@ import (
 func main() {
 	// https://developers.facebook.com/docs/messenger-platform/instagram/features/webhook
 
-	payload := []byte(`{
-		"object": "instagram",
-		"entry": [
-		  {
-			"id": "<IGID>",
-			"time": 1569262486134,
-			"messaging": [
-			  {
-				"sender": {
-				  "id": "<IGSID>"
-				},
-				"recipient": {
-				  "id": "<IGID>"
-				},
-				"timestamp": 1569262485349,
-				"message": {
-				  "mid": "<MESSAGE_ID>",
-				  "text": "<MESSAGE_CONTENT>"
-				}
-			  }
-			]
-		  }
-		],
-	}`)
-
-	webhookEvent := new(instabot.WebhookEvent)
-
-	if err := json.Unmarshal(payload, webhookEvent); err != nil {
-		log.Fatal("failed to unmarshal event")
-	}
+	// registering typed event handlers.
+	dispatcher := instabot.NewDispatcher()
+
+	dispatcher.OnTextMessage(func(ctx context.Context, event *instabot.TextMessageEvent) error {
+		log.Println(event)
+
+		return nil
+	})
+
+	dispatcher.OnMediaMessage(func(ctx context.Context, event *instabot.MediaMessageEvent) error {
+		log.Println(event)
+
+		return nil
+	})
+
+	dispatcher.OnQuickReply(func(ctx context.Context, event *instabot.QuickReplyEvent) error {
+		log.Println(event)
+
+		return nil
+	})
+
+	dispatcher.OnPostBack(func(ctx context.Context, event *instabot.PostBackEvent) error {
+		log.Println(event)
+
+		return nil
+	})
+
+	dispatcher.OnStoryMention(func(ctx context.Context, event *instabot.StoryMentionEvent) error {
+		log.Println(event)
+
+		return nil
+	})
+
+	dispatcher.OnStoryReply(func(ctx context.Context, event *instabot.StoryReplyEvent) error {
+		log.Println(event)
+
+		return nil
+	})
+
+	dispatcher.OnMessageReply(func(ctx context.Context, event *instabot.MessageReplyEvent) error {
+		log.Println(event)
+
+		return nil
+	})
 
-	for _, entry := range webhookEvent.Entries {
-		for _, event := range entry.Messaging {
-			switch event.Type {
-			case instabot.WebhookEventTypeEcho:
-			case instabot.WebhookEventTypeDeleted:
-				log.Println(event.GetMessageDeleteEvent())
-			case instabot.WebhookEventTypeUnsupported:
-			case instabot.WebhookEventTypeMessageSeen:
-				log.Println(event.GetMessageSeenEvent())
-			case instabot.WebhookEventTypeMessageReply:
-				log.Println(event.GetMessageReplyEvent())
-			case instabot.WebhookEventTypeShare:
-				log.Println(event.GetMessageShareEvent())
-			case instabot.WebhookEventTypeReaction:
-				log.Println(event.GetMessageReactionEvent())
-			case instabot.WebhookEventTypeTextMessage:
-				log.Println(event.GetTextMessageEvent())
-			case instabot.WebhookEventTypeImageMessage:
-				log.Println(event.GetMediaMessageEvent())
-			case instabot.WebhookEventTypeAudioMessage:
-				log.Println(event.GetMediaMessageEvent())
-			case instabot.WebhookEventTypeVideoMessage:
-				log.Println(event.GetMediaMessageEvent())
-			case instabot.WebhookEventTypeFileMessage:
-				log.Println(event.GetMediaMessageEvent())
-			case instabot.WebhookEventTypeQuickReply:
-				log.Println(event.GetQuickReplyEvent())
-			case instabot.WebhookEventTypePostBack:
-				log.Println(event.GetPostBackEvent())
-			case instabot.WebhookEventTypeStoryMention:
-				log.Println(event.GetStoryMentionEvent())
-			case instabot.WebhookEventTypeStoryReply:
-				log.Println(event.GetStoryReplyEvent())
-			default:
-				log.Println("unexpected event")
-			}
+	dispatcher.OnShare(func(ctx context.Context, event *instabot.MessageShareEvent) error {
+		log.Println(event)
+
+		return nil
+	})
+
+	dispatcher.OnReaction(func(ctx context.Context, event *instabot.MessageReactionEvent) error {
+		log.Println(event)
+
+		return nil
+	})
+
+	dispatcher.OnMessageSeen(func(ctx context.Context, event *instabot.MessageSeenEvent) error {
+		log.Println(event)
+
+		return nil
+	})
+
+	dispatcher.OnMessageDelete(func(ctx context.Context, event *instabot.MessageDeleteEvent) error {
+		log.Println(event)
+
+		return nil
+	})
+
+	// echoes of messages sent by the account, by this app,
+	// another app or a human from the instagram inbox.
+	dispatcher.OnEcho(func(ctx context.Context, event *instabot.EchoEvent) error {
+		if !event.IsSentByApp("your_app_id") {
+			log.Println("message sent outside of the bot", event.MID)
 		}
+
+		return nil
+	})
+
+	dispatcher.OnFallback(func(ctx context.Context, m *instabot.Messaging) error {
+		log.Println("unexpected event", m.Type)
+
+		return nil
+	})
+
+	// serving webhook, deliveries are verified with app secret
+	// and subscription verification is answered with verify token.
+	handler, err := instabot.NewWebhookHandler(
+		"your_app_secret",
+		dispatcher,
+		instabot.WithVerifyToken("your_verify_token"),
+	)
+	if err != nil {
+		log.Fatal(err)
 	}
+
+	http.Handle("/webhook", handler)
+
+	log.Fatal(http.ListenAndServe(":8080", nil))
 }
@@ -0,0 +1,85 @@
+package instabot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/url"
+)
+
+func encodeThreadControlJSON(w io.Writer, recipient string, targetAppID string, metadata string) error {
+	enc := json.NewEncoder(w)
+
+	return enc.Encode(&struct {
+		Recipient   *Recipient `json:"recipient"`
+		Platform    string     `json:"platform"`
+		TargetAppID string     `json:"target_app_id,omitempty"`
+		Metadata    string     `json:"metadata,omitempty"`
+	}{
+		Recipient: &Recipient{
+			ID: recipient,
+		},
+		Platform:    Platform,
+		TargetAppID: targetAppID,
+		Metadata:    metadata,
+	})
+}
+
+func (c *Client) threadControl(ctx context.Context, endpoint string, recipient string, targetAppID string, metadata string) (*ThreadControlResponse, error) {
+	var buf bytes.Buffer
+	if err := encodeThreadControlJSON(&buf, recipient, targetAppID, metadata); err != nil {
+		return nil, err
+	}
+
+	res, err := c.post(ctx, endpoint, &buf)
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	return decodeToThreadControlResponse(res)
+}
+
+// PassThreadControl passes conversation control to another app, e.g. a human inbox.
+// https://developers.facebook.com/docs/messenger-platform/instagram/features/handover-protocol#pass-thread-control
+func (c *Client) PassThreadControl(ctx context.Context, recipient string, targetAppID string, metadata string) (*ThreadControlResponse, error) {
+	return c.threadControl(ctx, APIEndpointPassThreadControl, recipient, targetAppID, metadata)
+}
+
+// TakeThreadControl takes conversation control from the current owner,
+// only the primary receiver app can take control.
+// https://developers.facebook.com/docs/messenger-platform/instagram/features/handover-protocol#take-thread-control
+func (c *Client) TakeThreadControl(ctx context.Context, recipient string, metadata string) (*ThreadControlResponse, error) {
+	return c.threadControl(ctx, APIEndpointTakeThreadControl, recipient, "", metadata)
+}
+
+// RequestThreadControl requests conversation control from the primary receiver app.
+// https://developers.facebook.com/docs/messenger-platform/instagram/features/handover-protocol#request-thread-control
+func (c *Client) RequestThreadControl(ctx context.Context, recipient string, metadata string) (*ThreadControlResponse, error) {
+	return c.threadControl(ctx, APIEndpointRequestThreadControl, recipient, "", metadata)
+}
+
+// ReleaseThreadControl releases conversation control back to the primary receiver app.
+// https://developers.facebook.com/docs/messenger-platform/instagram/features/handover-protocol#release-thread-control
+func (c *Client) ReleaseThreadControl(ctx context.Context, recipient string) (*ThreadControlResponse, error) {
+	return c.threadControl(ctx, APIEndpointReleaseThreadControl, recipient, "", "")
+}
+
+// GetThreadOwner fetches app currently owning the conversation.
+// https://developers.facebook.com/docs/messenger-platform/instagram/features/handover-protocol#thread-owner
+func (c *Client) GetThreadOwner(ctx context.Context, recipient string) (*GetThreadOwnerResponse, error) {
+	query := url.Values{}
+	query.Add("recipient", recipient)
+	query.Add("platform", Platform)
+
+	res, err := c.get(ctx, APIEndpointThreadOwner, query)
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	return decodeToGetThreadOwnerResponse(res)
+}
@@ -0,0 +1,257 @@
+package instabot
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThreadControl(t *testing.T) {
+	pageAccessToken := "page_access_token"
+	recipient := "test_recipient"
+
+	type args struct {
+		ctx  context.Context
+		call func(ctx context.Context, client *Client) (*ThreadControlResponse, error)
+	}
+
+	type fields struct {
+		wantEndpoint       string
+		wantRequestBody    string
+		returnResponse     string
+		returnResponseCode int
+	}
+
+	type test struct {
+		args    args
+		fields  fields
+		wantErr error
+		want    *ThreadControlResponse
+	}
+
+	tests := map[string]func(t *testing.T) test{
+		"pass thread control success": func(t *testing.T) test {
+			return test{
+				args: args{
+					ctx: context.Background(),
+					call: func(ctx context.Context, client *Client) (*ThreadControlResponse, error) {
+						return client.PassThreadControl(ctx, recipient, "263902037430900", "<METADATA>")
+					},
+				},
+				fields: fields{
+					wantEndpoint: APIEndpointPassThreadControl,
+					wantRequestBody: `{
+						"recipient": {
+							"id": "test_recipient"
+						},
+						"platform": "instagram",
+						"target_app_id": "263902037430900",
+						"metadata": "<METADATA>"
+					}`,
+					returnResponse:     `{"success": true}`,
+					returnResponseCode: 200,
+				},
+				want: &ThreadControlResponse{Success: true},
+			}
+		},
+		"take thread control success": func(t *testing.T) test {
+			return test{
+				args: args{
+					ctx: context.Background(),
+					call: func(ctx context.Context, client *Client) (*ThreadControlResponse, error) {
+						return client.TakeThreadControl(ctx, recipient, "<METADATA>")
+					},
+				},
+				fields: fields{
+					wantEndpoint: APIEndpointTakeThreadControl,
+					wantRequestBody: `{
+						"recipient": {
+							"id": "test_recipient"
+						},
+						"platform": "instagram",
+						"metadata": "<METADATA>"
+					}`,
+					returnResponse:     `{"success": true}`,
+					returnResponseCode: 200,
+				},
+				want: &ThreadControlResponse{Success: true},
+			}
+		},
+		"request thread control success": func(t *testing.T) test {
+			return test{
+				args: args{
+					ctx: context.Background(),
+					call: func(ctx context.Context, client *Client) (*ThreadControlResponse, error) {
+						return client.RequestThreadControl(ctx, recipient, "<METADATA>")
+					},
+				},
+				fields: fields{
+					wantEndpoint: APIEndpointRequestThreadControl,
+					wantRequestBody: `{
+						"recipient": {
+							"id": "test_recipient"
+						},
+						"platform": "instagram",
+						"metadata": "<METADATA>"
+					}`,
+					returnResponse:     `{"success": true}`,
+					returnResponseCode: 200,
+				},
+				want: &ThreadControlResponse{Success: true},
+			}
+		},
+		"release thread control success": func(t *testing.T) test {
+			return test{
+				args: args{
+					ctx: context.Background(),
+					call: func(ctx context.Context, client *Client) (*ThreadControlResponse, error) {
+						return client.ReleaseThreadControl(ctx, recipient)
+					},
+				},
+				fields: fields{
+					wantEndpoint: APIEndpointReleaseThreadControl,
+					wantRequestBody: `{
+						"recipient": {
+							"id": "test_recipient"
+						},
+						"platform": "instagram"
+					}`,
+					returnResponse:     `{"success": true}`,
+					returnResponseCode: 200,
+				},
+				want: &ThreadControlResponse{Success: true},
+			}
+		},
+		"pass thread control error": func(t *testing.T) test {
+			return test{
+				args: args{
+					ctx: context.Background(),
+					call: func(ctx context.Context, client *Client) (*ThreadControlResponse, error) {
+						return client.PassThreadControl(ctx, recipient, "263902037430900", "")
+					},
+				},
+				fields: fields{
+					wantEndpoint: APIEndpointPassThreadControl,
+					wantRequestBody: `{
+						"recipient": {
+							"id": "test_recipient"
+						},
+						"platform": "instagram",
+						"target_app_id": "263902037430900"
+					}`,
+					returnResponse: `{
+						"error": {
+							"message": "error",
+							"type": "OAuthException",
+							"code": 10,
+							"error_subcode": 2018300,
+							"fbtrace_id": "fbtrace_id"
+						}
+					}`,
+					returnResponseCode: 400,
+				},
+				want: nil,
+				wantErr: &ErrorResponse{
+					StatusCode: 400,
+					APIError: APIError{
+						Message:   "error",
+						Type:      "OAuthException",
+						Code:      10,
+						SubCode:   2018300,
+						FbTraceID: "fbtrace_id",
+					},
+				},
+			}
+		},
+	}
+
+	var currentTest string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tc := tests[currentTest](t)
+
+		assert.Equal(t, http.MethodPost, r.Method)
+
+		assert.Equal(t, tc.fields.wantEndpoint, r.URL.Path)
+
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.JSONEq(t, tc.fields.wantRequestBody, string(body))
+
+		w.WriteHeader(tc.fields.returnResponseCode)
+		w.Write([]byte(tc.fields.returnResponse))
+	}))
+	defer mockServer.Close()
+
+	for name, fn := range tests {
+		currentTest = name
+		tt := fn(t)
+
+		t.Run(name, func(t *testing.T) {
+			client, err := New(pageAccessToken, WithEndpointBase(mockServer.URL))
+			assert.NoError(t, err)
+
+			res, err := tt.args.call(tt.args.ctx, client)
+			if tt.wantErr != nil {
+				assert.EqualError(t, tt.wantErr, err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, tt.want, res)
+		})
+	}
+}
+
+func TestGetThreadOwner(t *testing.T) {
+	pageAccessToken := "page_access_token"
+	recipient := "test_recipient"
+
+	q := url.Values{}
+	q.Add("recipient", recipient)
+	q.Add("platform", Platform)
+	q.Add("access_token", pageAccessToken)
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+
+		assert.Equal(t, APIEndpointThreadOwner, r.URL.Path)
+
+		assert.Equal(t, q, r.URL.Query())
+
+		w.WriteHeader(200)
+		w.Write([]byte(`{
+			"data": [
+				{
+					"thread_owner": {
+						"app_id": "263902037430900"
+					}
+				}
+			]
+		}`))
+	}))
+	defer mockServer.Close()
+
+	client, err := New(pageAccessToken, WithEndpointBase(mockServer.URL))
+	assert.NoError(t, err)
+
+	res, err := client.GetThreadOwner(context.Background(), recipient)
+	assert.NoError(t, err)
+
+	assert.Equal(t, &GetThreadOwnerResponse{
+		Data: []ThreadOwnerData{
+			{
+				ThreadOwner: ThreadOwner{
+					AppID: "263902037430900",
+				},
+			},
+		},
+	}, res)
+}
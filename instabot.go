@@ -1,14 +1,34 @@
 package instabot
 
-import "context"
+import (
+	"context"
+	"io"
+)
 
 // InstaBot defines InstaBot client interface.
 type InstaBot interface {
-	SendMessage(ctx context.Context, recipient string, message Message) (*SendMessageResponse, error)
+	SendMessage(ctx context.Context, recipient string, message Message, options ...SendOption) (*SendMessageResponse, error)
+	SendPrivateReply(ctx context.Context, commentID string, message Message, options ...PrivateReplyOption) (*SendMessageResponse, error)
+	SendSenderAction(ctx context.Context, recipient string, action SenderAction) (*SenderActionResponse, error)
+	SendReaction(ctx context.Context, recipient string, mid string, reaction ReactionType) (*SenderActionResponse, error)
+	RemoveReaction(ctx context.Context, recipient string, mid string) (*SenderActionResponse, error)
+	UploadAttachment(ctx context.Context, attachmentType MessageType, r io.Reader, filename string) (*UploadAttachmentResponse, error)
+	UploadAttachmentFromURL(ctx context.Context, attachmentType MessageType, url string) (*UploadAttachmentResponse, error)
 	SetIceBreakers(ctx context.Context, iceBreakers []*IceBreaker) (*SetIceBreakersResponse, error)
 	GetIceBreakers(ctx context.Context) (*GetIceBreakersResponse, error)
 	DeleteIceBreakers(ctx context.Context) (*DeleteIceBreakersResponse, error)
 	GetUserProfile(ctx context.Context, instagramUserID string) (*GetUserProfileResponse, error)
+	PassThreadControl(ctx context.Context, recipient string, targetAppID string, metadata string) (*ThreadControlResponse, error)
+	TakeThreadControl(ctx context.Context, recipient string, metadata string) (*ThreadControlResponse, error)
+	RequestThreadControl(ctx context.Context, recipient string, metadata string) (*ThreadControlResponse, error)
+	ReleaseThreadControl(ctx context.Context, recipient string) (*ThreadControlResponse, error)
+	GetThreadOwner(ctx context.Context, recipient string) (*GetThreadOwnerResponse, error)
+	SubscribeApp(ctx context.Context, pageID string, fields []WebhookField) (*SubscriptionResponse, error)
+	UnsubscribeApp(ctx context.Context, pageID string) (*SubscriptionResponse, error)
+	GetSubscribedApps(ctx context.Context, pageID string) (*GetSubscribedAppsResponse, error)
+	SetAppSubscription(ctx context.Context, callbackURL string, verifyToken string, fields []WebhookField) (*SubscriptionResponse, error)
+	GetAppSubscriptions(ctx context.Context) (*GetAppSubscriptionsResponse, error)
+	DeleteAppSubscription(ctx context.Context) (*SubscriptionResponse, error)
 }
 
 // compile time interface implementation check.
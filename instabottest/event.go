@@ -0,0 +1,293 @@
+// Package instabottest provides utilities to build fake instagram
+// webhook events and deliver them to webhook handlers in tests.
+package instabottest
+
+import (
+	"encoding/json"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/BackAged/instabot"
+)
+
+// DefaultAccountID is id of the instagram account receiving events,
+// unless changed with WithRecipient.
+const DefaultAccountID = "17841400000000000"
+
+var midCounter uint64
+
+// nextMID returns unique message id so that
+// built events are never taken for redeliveries.
+func nextMID() string {
+	return "mid." + strconv.FormatUint(atomic.AddUint64(&midCounter, 1), 10)
+}
+
+type event struct {
+	messaging *instabot.Messaging
+	accountID string
+	standby   bool
+}
+
+// EventOption defines optional argument of event builders.
+type EventOption func(*event)
+
+// WithRecipient sets id of the instagram account receiving the event.
+func WithRecipient(accountID string) EventOption {
+	return func(e *event) {
+		e.accountID = accountID
+	}
+}
+
+// WithTimestamp sets time of the event, defaults to now.
+func WithTimestamp(t time.Time) EventOption {
+	return func(e *event) {
+		e.messaging.Timestamp = t.UnixNano() / int64(time.Millisecond)
+	}
+}
+
+// WithMID sets message id of message events, a unique one
+// is generated by default.
+func WithMID(mid string) EventOption {
+	return func(e *event) {
+		switch {
+		case e.messaging.Message != nil:
+			e.messaging.Message.MID = mid
+		case e.messaging.PostBack != nil:
+			e.messaging.PostBack.MID = mid
+		}
+	}
+}
+
+// WithReferral attaches referral to message and postback events.
+func WithReferral(ref string, source string) EventOption {
+	return func(e *event) {
+		referral := &instabot.Referral{Ref: ref, Source: source, Type: "OPEN_THREAD"}
+
+		switch {
+		case e.messaging.Message != nil:
+			e.messaging.Message.Referral = referral
+		case e.messaging.PostBack != nil:
+			e.messaging.PostBack.Referral = referral
+		}
+	}
+}
+
+// WithStandby delivers the event under entry standby,
+// as if another app owned the conversation.
+func WithStandby() EventOption {
+	return func(e *event) {
+		e.standby = true
+	}
+}
+
+// build wraps messaging between user and the account into a webhook event
+// and decodes it back through instabot, so that the event is typed exactly
+// like a delivered one.
+func build(user string, messaging *instabot.Messaging, opts []EventOption) *instabot.WebhookEvent {
+	messaging.Timestamp = time.Now().UnixNano() / int64(time.Millisecond)
+
+	e := &event{messaging: messaging, accountID: DefaultAccountID}
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	if messaging.Message != nil && messaging.Message.IsEcho {
+		messaging.Sender = &instabot.Sender{ID: e.accountID}
+		messaging.Recipient = &instabot.Recipient{ID: user}
+	} else {
+		messaging.Sender = &instabot.Sender{ID: user}
+		messaging.Recipient = &instabot.Recipient{ID: e.accountID}
+	}
+
+	entry := &instabot.Entry{
+		ID:   e.accountID,
+		Time: messaging.Timestamp,
+	}
+
+	if e.standby {
+		entry.Standby = []*instabot.Messaging{messaging}
+	} else {
+		entry.Messaging = []*instabot.Messaging{messaging}
+	}
+
+	return decode(&instabot.WebhookEvent{
+		Object:  "instagram",
+		Entries: []*instabot.Entry{entry},
+	})
+}
+
+func decode(event *instabot.WebhookEvent) *instabot.WebhookEvent {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		panic("instabottest: " + err.Error())
+	}
+
+	decoded := new(instabot.WebhookEvent)
+	if err := json.Unmarshal(payload, decoded); err != nil {
+		panic("instabottest: " + err.Error())
+	}
+
+	return decoded
+}
+
+// Merge returns a webhook event holding entries of all the given events,
+// like a batched delivery.
+func Merge(events ...*instabot.WebhookEvent) *instabot.WebhookEvent {
+	merged := &instabot.WebhookEvent{Object: "instagram"}
+
+	for _, e := range events {
+		merged.Entries = append(merged.Entries, e.Entries...)
+	}
+
+	return decode(merged)
+}
+
+// NewTextEvent returns text message event sent by sender.
+func NewTextEvent(sender string, text string, opts ...EventOption) *instabot.WebhookEvent {
+	return build(sender, &instabot.Messaging{
+		Message: &instabot.WebhookMessage{
+			MID:  nextMID(),
+			Text: text,
+		},
+	}, opts)
+}
+
+// NewQuickReplyEvent returns quick reply event sent by sender.
+func NewQuickReplyEvent(sender string, text string, payload string, opts ...EventOption) *instabot.WebhookEvent {
+	return build(sender, &instabot.Messaging{
+		Message: &instabot.WebhookMessage{
+			MID:        nextMID(),
+			Text:       text,
+			QuickReply: &instabot.WebhookQuickReply{Payload: payload},
+		},
+	}, opts)
+}
+
+// NewPostBackEvent returns postback event of a button tapped by sender.
+func NewPostBackEvent(sender string, title string, payload string, opts ...EventOption) *instabot.WebhookEvent {
+	return build(sender, &instabot.Messaging{
+		PostBack: &instabot.Postback{
+			MID:     nextMID(),
+			Title:   title,
+			Payload: payload,
+		},
+	}, opts)
+}
+
+// NewMediaEvent returns message event with one attachment per url,
+// attachmentType being image, audio, video or file.
+func NewMediaEvent(sender string, attachmentType instabot.WebhookEventType, urls []string, opts ...EventOption) *instabot.WebhookEvent {
+	attachments := make([]*instabot.Attachment, 0, len(urls))
+	for _, url := range urls {
+		attachments = append(attachments, &instabot.Attachment{
+			Type:    string(attachmentType),
+			Payload: instabot.AttachmentPayload{URL: url},
+		})
+	}
+
+	return build(sender, &instabot.Messaging{
+		Message: &instabot.WebhookMessage{
+			MID:         nextMID(),
+			Attachments: attachments,
+		},
+	}, opts)
+}
+
+// NewShareEvent returns share event of a post shared by sender.
+func NewShareEvent(sender string, url string, opts ...EventOption) *instabot.WebhookEvent {
+	return NewMediaEvent(sender, instabot.WebhookEventTypeShare, []string{url}, opts...)
+}
+
+// NewStoryMention returns story mention event of a story of sender
+// mentioning the account.
+func NewStoryMention(sender string, storyURL string, opts ...EventOption) *instabot.WebhookEvent {
+	return NewMediaEvent(sender, instabot.WebhookEventTypeStoryMention, []string{storyURL}, opts...)
+}
+
+// NewStoryReply returns story reply event of sender replying to a story of the account.
+func NewStoryReply(sender string, text string, storyID string, storyURL string, opts ...EventOption) *instabot.WebhookEvent {
+	return build(sender, &instabot.Messaging{
+		Message: &instabot.WebhookMessage{
+			MID:  nextMID(),
+			Text: text,
+			ReplyTo: &instabot.ReplyTo{
+				Story: &instabot.ReplyToStory{ID: storyID, URL: storyURL},
+			},
+		},
+	}, opts)
+}
+
+// NewMessageReply returns message reply event of sender replying to message mid.
+func NewMessageReply(sender string, text string, mid string, opts ...EventOption) *instabot.WebhookEvent {
+	return build(sender, &instabot.Messaging{
+		Message: &instabot.WebhookMessage{
+			MID:     nextMID(),
+			Text:    text,
+			ReplyTo: &instabot.ReplyTo{MID: mid},
+		},
+	}, opts)
+}
+
+// NewReaction returns reaction event of sender reacting to message mid.
+func NewReaction(sender string, mid string, reaction string, opts ...EventOption) *instabot.WebhookEvent {
+	return build(sender, &instabot.Messaging{
+		Reaction: &instabot.Reaction{
+			MID:      mid,
+			Action:   "react",
+			Reaction: reaction,
+		},
+	}, opts)
+}
+
+// NewUnreaction returns reaction event of sender removing reaction of message mid.
+func NewUnreaction(sender string, mid string, opts ...EventOption) *instabot.WebhookEvent {
+	return build(sender, &instabot.Messaging{
+		Reaction: &instabot.Reaction{
+			MID:    mid,
+			Action: "unreact",
+		},
+	}, opts)
+}
+
+// NewMessageSeen returns message seen event of sender reading message mid.
+func NewMessageSeen(sender string, mid string, opts ...EventOption) *instabot.WebhookEvent {
+	return build(sender, &instabot.Messaging{
+		Read: &instabot.Read{MID: mid},
+	}, opts)
+}
+
+// NewMessageDelete returns event of sender deleting message mid.
+func NewMessageDelete(sender string, mid string, opts ...EventOption) *instabot.WebhookEvent {
+	return build(sender, &instabot.Messaging{
+		Message: &instabot.WebhookMessage{
+			MID:       mid,
+			IsDeleted: true,
+		},
+	}, opts)
+}
+
+// NewReferral returns referral event of sender opening the conversation
+// from an ig.me link or an ad.
+func NewReferral(sender string, ref string, source string, opts ...EventOption) *instabot.WebhookEvent {
+	return build(sender, &instabot.Messaging{
+		Referral: &instabot.Referral{
+			Ref:    ref,
+			Source: source,
+			Type:   "OPEN_THREAD",
+		},
+	}, opts)
+}
+
+// NewEcho returns echo event of a text message sent by the account to
+// recipient from the app appID, an empty appID as if sent from the inbox.
+func NewEcho(recipient string, text string, appID instabot.AppID, opts ...EventOption) *instabot.WebhookEvent {
+	return build(recipient, &instabot.Messaging{
+		Message: &instabot.WebhookMessage{
+			MID:    nextMID(),
+			Text:   text,
+			AppID:  appID,
+			IsEcho: true,
+		},
+	}, opts)
+}
@@ -0,0 +1,156 @@
+package instabottest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/BackAged/instabot"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBuilders(t *testing.T) {
+	testCases := []struct {
+		name      string
+		args      *instabot.WebhookEvent
+		want      instabot.WebhookEventType
+		afterEach func(t *testing.T, m *instabot.Messaging)
+	}{
+		{
+			name: "text event",
+			args: NewTextEvent("<IGSID>", "hello"),
+			want: instabot.WebhookEventTypeTextMessage,
+			afterEach: func(t *testing.T, m *instabot.Messaging) {
+				assert.Equal(t, "hello", m.GetTextMessageEvent().Text)
+				assert.Equal(t, "<IGSID>", m.Sender.ID)
+				assert.Equal(t, DefaultAccountID, m.Recipient.ID)
+				assert.NotEmpty(t, m.Message.MID)
+			},
+		},
+		{
+			name: "quick reply event",
+			args: NewQuickReplyEvent("<IGSID>", "Red", "COLOR_RED"),
+			want: instabot.WebhookEventTypeQuickReply,
+			afterEach: func(t *testing.T, m *instabot.Messaging) {
+				assert.Equal(t, "COLOR_RED", m.GetQuickReplyEvent().Data.Payload)
+			},
+		},
+		{
+			name: "postback event with referral",
+			args: NewPostBackEvent("<IGSID>", "Start", "GET_STARTED", WithReferral("campaign", "ADS")),
+			want: instabot.WebhookEventTypePostBack,
+			afterEach: func(t *testing.T, m *instabot.Messaging) {
+				assert.Equal(t, "GET_STARTED", m.GetPostBackEvent().Data.Payload)
+				assert.True(t, m.HasReferral())
+				assert.Equal(t, "campaign", m.GetReferralEvent().Ref)
+			},
+		},
+		{
+			name: "media event",
+			args: NewMediaEvent("<IGSID>", instabot.WebhookEventTypeImageMessage, []string{"<URL_1>", "<URL_2>"}),
+			want: instabot.WebhookEventTypeImageMessage,
+			afterEach: func(t *testing.T, m *instabot.Messaging) {
+				assert.Len(t, m.GetMediaMessageEvent().Attachments, 2)
+			},
+		},
+		{
+			name: "story mention event",
+			args: NewStoryMention("<IGSID>", "<STORY_URL>"),
+			want: instabot.WebhookEventTypeStoryMention,
+			afterEach: func(t *testing.T, m *instabot.Messaging) {
+				assert.Equal(t, "<STORY_URL>", m.GetStoryMentionEvent().Story.URL)
+			},
+		},
+		{
+			name: "story reply event",
+			args: NewStoryReply("<IGSID>", "nice", "<STORY_ID>", "<STORY_URL>"),
+			want: instabot.WebhookEventTypeStoryReply,
+		},
+		{
+			name: "message reply event",
+			args: NewMessageReply("<IGSID>", "yes", "<MESSAGE_ID>"),
+			want: instabot.WebhookEventTypeMessageReply,
+		},
+		{
+			name: "reaction event",
+			args: NewReaction("<IGSID>", "<MESSAGE_ID>", "love"),
+			want: instabot.WebhookEventTypeReaction,
+			afterEach: func(t *testing.T, m *instabot.Messaging) {
+				assert.Equal(t, "love", m.Reaction.Reaction)
+			},
+		},
+		{
+			name: "message seen event",
+			args: NewMessageSeen("<IGSID>", "<MESSAGE_ID>"),
+			want: instabot.WebhookEventTypeMessageSeen,
+		},
+		{
+			name: "message delete event",
+			args: NewMessageDelete("<IGSID>", "<MESSAGE_ID>"),
+			want: instabot.WebhookEventTypeDeleted,
+		},
+		{
+			name: "referral event",
+			args: NewReferral("<IGSID>", "campaign", "ADS"),
+			want: instabot.WebhookEventTypeReferral,
+		},
+		{
+			name: "echo event",
+			args: NewEcho("<IGSID>", "hi", "<APP_ID>", WithRecipient("<IGID>")),
+			want: instabot.WebhookEventTypeEcho,
+			afterEach: func(t *testing.T, m *instabot.Messaging) {
+				assert.Equal(t, "<IGID>", m.Sender.ID)
+				assert.Equal(t, "<IGSID>", m.Recipient.ID)
+				assert.True(t, m.GetEchoEvent().IsSentByApp("<APP_ID>"))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, "instagram", tc.args.Object)
+			assert.Len(t, tc.args.Entries, 1)
+			assert.Len(t, tc.args.Entries[0].Messaging, 1)
+
+			m := tc.args.Entries[0].Messaging[0]
+			assert.Equal(t, tc.want, m.Type)
+
+			if tc.afterEach != nil {
+				tc.afterEach(t, m)
+			}
+		})
+	}
+}
+
+func TestEventOptions(t *testing.T) {
+	ts := time.Date(2021, time.June, 1, 10, 0, 0, 0, time.UTC)
+
+	e := NewTextEvent(
+		"<IGSID>", "hello",
+		WithRecipient("<IGID>"),
+		WithTimestamp(ts),
+		WithMID("<MESSAGE_ID>"),
+		WithStandby(),
+	)
+
+	entry := e.Entries[0]
+	assert.Equal(t, "<IGID>", entry.ID)
+	assert.Empty(t, entry.Messaging)
+	assert.Len(t, entry.Standby, 1)
+
+	m := entry.Standby[0]
+	assert.True(t, m.Standby)
+	assert.Equal(t, "<IGID>", m.Recipient.ID)
+	assert.Equal(t, ts, m.GetTextMessageEvent().Timestamp)
+	assert.Equal(t, "<MESSAGE_ID>", m.Message.MID)
+}
+
+func TestMerge(t *testing.T) {
+	e := Merge(
+		NewTextEvent("<IGSID>", "hello"),
+		NewMessageSeen("<IGSID>", "<MESSAGE_ID>"),
+	)
+
+	assert.Len(t, e.Entries, 2)
+	assert.Equal(t, instabot.WebhookEventTypeTextMessage, e.Entries[0].Messaging[0].Type)
+	assert.Equal(t, instabot.WebhookEventTypeMessageSeen, e.Entries[1].Messaging[0].Type)
+}
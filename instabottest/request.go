@@ -0,0 +1,53 @@
+package instabottest
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/BackAged/instabot"
+)
+
+// Sign returns X-Hub-Signature-256 header value of the payload.
+func Sign(appSecret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(appSecret))
+	mac.Write(payload)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewRequest returns webhook delivery request of the event
+// signed with appSecret, suitable for passing to an http.Handler.
+func NewRequest(appSecret string, event *instabot.WebhookEvent) *http.Request {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		panic("instabottest: " + err.Error())
+	}
+
+	return NewRawRequest(appSecret, payload)
+}
+
+// NewRawRequest returns webhook delivery request of the raw payload
+// signed with appSecret.
+func NewRawRequest(appSecret string, payload []byte) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(payload))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set(instabot.HeaderHubSignature256, Sign(appSecret, payload))
+
+	return r
+}
+
+// NewVerificationRequest returns webhook subscription verification request.
+func NewVerificationRequest(verifyToken string, challenge string) *http.Request {
+	q := url.Values{}
+	q.Set(instabot.QueryHubMode, instabot.HubModeSubscribe)
+	q.Set(instabot.QueryHubVerifyToken, verifyToken)
+	q.Set(instabot.QueryHubChallenge, challenge)
+
+	return httptest.NewRequest(http.MethodGet, "/webhook?"+q.Encode(), nil)
+}
@@ -0,0 +1,54 @@
+package instabottest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/BackAged/instabot"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRequest(t *testing.T) {
+	var got []string
+
+	d := instabot.NewDispatcher()
+	d.OnTextMessage(func(ctx context.Context, event *instabot.TextMessageEvent) error {
+		got = append(got, event.Text)
+
+		return nil
+	})
+
+	handler, err := instabot.NewWebhookHandler(
+		"app_secret",
+		d,
+		instabot.WithVerifyToken("verify_token"),
+	)
+	assert.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, NewRequest("app_secret", NewTextEvent("<IGSID>", "hello")))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"hello"}, got)
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, NewRequest("wrong_secret", NewTextEvent("<IGSID>", "hello")))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, []string{"hello"}, got)
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, NewVerificationRequest("verify_token", "challenge"))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "challenge", w.Body.String())
+}
+
+func TestSign(t *testing.T) {
+	payload := []byte(`{"object":"instagram"}`)
+
+	header := http.Header{}
+	header.Set(instabot.HeaderHubSignature256, Sign("app_secret", payload))
+
+	assert.NoError(t, instabot.VerifySignature([]byte("app_secret"), payload, header))
+	assert.Error(t, instabot.VerifySignature([]byte("other_secret"), payload, header))
+}
@@ -17,10 +17,17 @@ type MessageType string
 const (
 	MessageTypeText       MessageType = MessageType("text")
 	MessageTypeImage      MessageType = MessageType("image")
+	MessageTypeAudio      MessageType = MessageType("audio")
+	MessageTypeVideo      MessageType = MessageType("video")
+	MessageTypeFile       MessageType = MessageType("file")
 	MessageTypeSticker    MessageType = MessageType("sticker")
 	MessageTypeMediaShare MessageType = MessageType("media_share")
-	MessageTypeReacton    MessageType = MessageType("reaction")
+	MessageTypeReaction   MessageType = MessageType("reaction")
 	MessageTypeTemplate   MessageType = MessageType("template")
+
+	// Deprecated: MessageTypeReacton is misspelled, use MessageTypeReaction.
+	// Reactions are sent with Client.SendReaction.
+	MessageTypeReacton = MessageTypeReaction
 )
 
 // TextMessage defines text message.
@@ -76,10 +83,11 @@ func (m *TextMessage) MarshalJSON() ([]byte, error) {
 	})
 }
 
-// ImageMessage defines image message.
+// ImageMessage defines image message, sent either by url or by attachment id.
 type ImageMessage struct {
-	messageType MessageType
-	ImageURL    string
+	messageType  MessageType
+	ImageURL     string
+	AttachmentID string
 }
 
 // NewImageMessage returns a new image message.
@@ -90,6 +98,14 @@ func NewImageMessage(imageURL string) *ImageMessage {
 	}
 }
 
+// NewImageMessageFromAttachment returns a new image message of a previously saved attachment.
+func NewImageMessageFromAttachment(attachmentID string) *ImageMessage {
+	return &ImageMessage{
+		messageType:  MessageTypeImage,
+		AttachmentID: attachmentID,
+	}
+}
+
 // Type returns message type.
 func (m *ImageMessage) Type() MessageType {
 	return m.messageType
@@ -97,8 +113,40 @@ func (m *ImageMessage) Type() MessageType {
 
 // MarshalJSON returns json of the message.
 func (m *ImageMessage) MarshalJSON() ([]byte, error) {
+	return marshalAttachmentMessage(m.messageType, m.ImageURL, m.AttachmentID, false)
+}
+
+// AttachmentMessageOption defines optional argument for new audio, video and file message construction.
+type AttachmentMessageOption func(*attachmentMessageOptions)
+
+type attachmentMessageOptions struct {
+	isReusable bool
+}
+
+// WithReusableAttachment asks instagram to save the attachment sent by url,
+// so that its attachment id could be reused in later messages.
+func WithReusableAttachment() AttachmentMessageOption {
+	return func(o *attachmentMessageOptions) {
+		o.isReusable = true
+	}
+}
+
+func newAttachmentMessageOptions(options []AttachmentMessageOption) *attachmentMessageOptions {
+	o := &attachmentMessageOptions{}
+	for _, option := range options {
+		option(o)
+	}
+
+	return o
+}
+
+// marshalAttachmentMessage returns json of a media attachment
+// sent either by url or by attachment id.
+func marshalAttachmentMessage(messageType MessageType, url string, attachmentID string, isReusable bool) ([]byte, error) {
 	type Payload struct {
-		ImageURL string `json:"url"`
+		URL          string `json:"url,omitempty"`
+		AttachmentID string `json:"attachment_id,omitempty"`
+		IsReusable   bool   `json:"is_reusable,omitempty"`
 	}
 
 	type Attachment struct {
@@ -110,14 +158,145 @@ func (m *ImageMessage) MarshalJSON() ([]byte, error) {
 		Attachment *Attachment `json:"attachment"`
 	}{
 		Attachment: &Attachment{
-			Type: string(m.messageType),
+			Type: string(messageType),
 			Payload: &Payload{
-				ImageURL: m.ImageURL,
+				URL:          url,
+				AttachmentID: attachmentID,
+				IsReusable:   isReusable,
 			},
 		},
 	})
 }
 
+// AudioMessage defines audio message, sent either by url or by attachment id.
+type AudioMessage struct {
+	messageType  MessageType
+	URL          string
+	AttachmentID string
+	IsReusable   bool
+}
+
+// NewAudioMessage returns a new audio message of the audio at url.
+func NewAudioMessage(url string, options ...AttachmentMessageOption) *AudioMessage {
+	o := newAttachmentMessageOptions(options)
+
+	return &AudioMessage{
+		messageType: MessageTypeAudio,
+		URL:         url,
+		IsReusable:  o.isReusable,
+	}
+}
+
+// NewAudioMessageFromAttachment returns a new audio message of a previously saved attachment.
+func NewAudioMessageFromAttachment(attachmentID string) *AudioMessage {
+	return &AudioMessage{
+		messageType:  MessageTypeAudio,
+		AttachmentID: attachmentID,
+	}
+}
+
+// Type returns message type.
+func (m *AudioMessage) Type() MessageType {
+	return m.messageType
+}
+
+// MarshalJSON returns json of the message.
+func (m *AudioMessage) MarshalJSON() ([]byte, error) {
+	return marshalAttachmentMessage(m.messageType, m.URL, m.AttachmentID, m.IsReusable)
+}
+
+// VideoMessage defines video message, sent either by url or by attachment id.
+type VideoMessage struct {
+	messageType  MessageType
+	URL          string
+	AttachmentID string
+	IsReusable   bool
+}
+
+// NewVideoMessage returns a new video message of the video at url.
+func NewVideoMessage(url string, options ...AttachmentMessageOption) *VideoMessage {
+	o := newAttachmentMessageOptions(options)
+
+	return &VideoMessage{
+		messageType: MessageTypeVideo,
+		URL:         url,
+		IsReusable:  o.isReusable,
+	}
+}
+
+// NewVideoMessageFromAttachment returns a new video message of a previously saved attachment.
+func NewVideoMessageFromAttachment(attachmentID string) *VideoMessage {
+	return &VideoMessage{
+		messageType:  MessageTypeVideo,
+		AttachmentID: attachmentID,
+	}
+}
+
+// Type returns message type.
+func (m *VideoMessage) Type() MessageType {
+	return m.messageType
+}
+
+// MarshalJSON returns json of the message.
+func (m *VideoMessage) MarshalJSON() ([]byte, error) {
+	return marshalAttachmentMessage(m.messageType, m.URL, m.AttachmentID, m.IsReusable)
+}
+
+// FileMessage defines file message, sent either by url or by attachment id.
+type FileMessage struct {
+	messageType  MessageType
+	URL          string
+	AttachmentID string
+	IsReusable   bool
+}
+
+// NewFileMessage returns a new file message of the file at url.
+func NewFileMessage(url string, options ...AttachmentMessageOption) *FileMessage {
+	o := newAttachmentMessageOptions(options)
+
+	return &FileMessage{
+		messageType: MessageTypeFile,
+		URL:         url,
+		IsReusable:  o.isReusable,
+	}
+}
+
+// NewFileMessageFromAttachment returns a new file message of a previously saved attachment.
+func NewFileMessageFromAttachment(attachmentID string) *FileMessage {
+	return &FileMessage{
+		messageType:  MessageTypeFile,
+		AttachmentID: attachmentID,
+	}
+}
+
+// Type returns message type.
+func (m *FileMessage) Type() MessageType {
+	return m.messageType
+}
+
+// MarshalJSON returns json of the message.
+func (m *FileMessage) MarshalJSON() ([]byte, error) {
+	return marshalAttachmentMessage(m.messageType, m.URL, m.AttachmentID, m.IsReusable)
+}
+
+// NewAttachmentMessage returns a new message of a previously saved attachment
+// of the given type, one of image, audio, video or file, like one returned by
+// Client.UploadAttachment.
+func NewAttachmentMessage(attachmentType MessageType, attachmentID string) (Message, error) {
+	switch attachmentType {
+	case MessageTypeImage:
+		return NewImageMessageFromAttachment(attachmentID), nil
+	case MessageTypeAudio:
+		return NewAudioMessageFromAttachment(attachmentID), nil
+	case MessageTypeVideo:
+		return NewVideoMessageFromAttachment(attachmentID), nil
+	case MessageTypeFile:
+		return NewFileMessageFromAttachment(attachmentID), nil
+	}
+
+	return nil, ErrInvalidAttachmentType
+}
+
 // StickerType defines sticker type.
 type StickerType string
 
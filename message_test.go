@@ -24,6 +24,21 @@ func TestMessageType(t *testing.T) {
 			args: NewImageMessage("www.image.com"),
 			want: MessageTypeImage,
 		},
+		{
+			name: "audio message",
+			args: NewAudioMessage("www.audio.com"),
+			want: MessageTypeAudio,
+		},
+		{
+			name: "video message",
+			args: NewVideoMessage("www.video.com"),
+			want: MessageTypeVideo,
+		},
+		{
+			name: "file message",
+			args: NewFileMessageFromAttachment("1000"),
+			want: MessageTypeFile,
+		},
 		{
 			name: "sticker message",
 			args: NewStickerMessage(StickerTypeHeart),
@@ -140,6 +155,67 @@ func TestMessageJSON(t *testing.T) {
 			  	}
 			}`,
 		},
+		{
+			name: "audio message",
+			args: NewAudioMessage("<ASSET_URL>"),
+			want: `{
+				"attachment": {
+					"type": "audio",
+					"payload": {
+						"url": "<ASSET_URL>"
+					}
+				}
+			}`,
+		},
+		{
+			name: "reusable video message",
+			args: NewVideoMessage("<ASSET_URL>", WithReusableAttachment()),
+			want: `{
+				"attachment": {
+					"type": "video",
+					"payload": {
+						"url": "<ASSET_URL>",
+						"is_reusable": true
+					}
+				}
+			}`,
+		},
+		{
+			name: "file message",
+			args: NewFileMessage("<ASSET_URL>"),
+			want: `{
+				"attachment": {
+					"type": "file",
+					"payload": {
+						"url": "<ASSET_URL>"
+					}
+				}
+			}`,
+		},
+		{
+			name: "image message from attachment",
+			args: NewImageMessageFromAttachment("<ATTACHMENT_ID>"),
+			want: `{
+				"attachment": {
+					"type": "image",
+					"payload": {
+						"attachment_id": "<ATTACHMENT_ID>"
+					}
+				}
+			}`,
+		},
+		{
+			name: "file message from attachment",
+			args: NewFileMessageFromAttachment("<ATTACHMENT_ID>"),
+			want: `{
+				"attachment": {
+					"type": "file",
+					"payload": {
+						"attachment_id": "<ATTACHMENT_ID>"
+					}
+				}
+			}`,
+		},
 		{
 			name: "sticker message",
 			args: NewStickerMessage(StickerTypeHeart),
@@ -306,3 +382,46 @@ func TestMessageJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestNewAttachmentMessage(t *testing.T) {
+	testCases := []struct {
+		name           string
+		attachmentType MessageType
+		want           Message
+		wantErr        error
+	}{
+		{
+			name:           "image",
+			attachmentType: MessageTypeImage,
+			want:           NewImageMessageFromAttachment("1000"),
+		},
+		{
+			name:           "audio",
+			attachmentType: MessageTypeAudio,
+			want:           NewAudioMessageFromAttachment("1000"),
+		},
+		{
+			name:           "video",
+			attachmentType: MessageTypeVideo,
+			want:           NewVideoMessageFromAttachment("1000"),
+		},
+		{
+			name:           "file",
+			attachmentType: MessageTypeFile,
+			want:           NewFileMessageFromAttachment("1000"),
+		},
+		{
+			name:           "invalid attachment type",
+			attachmentType: MessageTypeText,
+			wantErr:        ErrInvalidAttachmentType,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := NewAttachmentMessage(tc.attachmentType, "1000")
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package instabot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Middleware wraps a messaging handler to add cross-cutting behaviour
+// like logging, panic recovery or filtering.
+type Middleware func(next MessagingHandlerFunc) MessagingHandlerFunc
+
+// Use appends messaging event middlewares to the dispatcher.
+// Middlewares run in the order they are added,
+// the first one added being the outermost.
+func (d *Dispatcher) Use(middlewares ...Middleware) {
+	d.middlewares = append(d.middlewares, middlewares...)
+}
+
+// ChangeMiddleware wraps a feed change handler, like Middleware
+// wraps a messaging handler.
+type ChangeMiddleware func(next ChangeHandlerFunc) ChangeHandlerFunc
+
+// UseChange appends feed change event middlewares to the dispatcher.
+// Middlewares run in the order they are added,
+// the first one added being the outermost.
+func (d *Dispatcher) UseChange(middlewares ...ChangeMiddleware) {
+	d.changeMiddlewares = append(d.changeMiddlewares, middlewares...)
+}
+
+func chainChangeMiddlewares(handler ChangeHandlerFunc, middlewares []ChangeMiddleware) ChangeHandlerFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+
+	return handler
+}
+
+func chainMiddlewares(handler MessagingHandlerFunc, middlewares []Middleware) MessagingHandlerFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+
+	return handler
+}
+
+// Recover returns middleware recovering from handler panics,
+// the panic is returned as an error wrapping ErrHandlerPanic.
+func Recover() Middleware {
+	return func(next MessagingHandlerFunc) MessagingHandlerFunc {
+		return func(ctx context.Context, m *Messaging) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					err = fmt.Errorf("%w: %v", ErrHandlerPanic, r)
+				}
+			}()
+
+			return next(ctx, m)
+		}
+	}
+}
+
+// RecoverChange returns change middleware recovering from handler panics,
+// the panic is returned as an error wrapping ErrHandlerPanic.
+func RecoverChange() ChangeMiddleware {
+	return func(next ChangeHandlerFunc) ChangeHandlerFunc {
+		return func(ctx context.Context, c *Change) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					err = fmt.Errorf("%w: %v", ErrHandlerPanic, r)
+				}
+			}()
+
+			return next(ctx, c)
+		}
+	}
+}
+
+// Logger returns middleware logging every handled event with
+// its type, sender, handling duration and error if any.
+// The standard logger output is used when logger is nil.
+func Logger(logger *log.Logger) Middleware {
+	printf := log.Printf
+	if logger != nil {
+		printf = logger.Printf
+	}
+
+	return func(next MessagingHandlerFunc) MessagingHandlerFunc {
+		return func(ctx context.Context, m *Messaging) error {
+			start := time.Now()
+
+			err := next(ctx, m)
+
+			sender := ""
+			if m.Sender != nil {
+				sender = m.Sender.ID
+			}
+
+			printf(
+				"type=%s sender=%s duration=%s error=%v",
+				m.Type, sender, time.Since(start), err,
+			)
+
+			return err
+		}
+	}
+}
+
+// LoggerChange returns change middleware logging every handled change with
+// its type, account, handling duration and error if any.
+// The standard logger is used when logger is nil.
+func LoggerChange(logger *log.Logger) ChangeMiddleware {
+	printf := log.Printf
+	if logger != nil {
+		printf = logger.Printf
+	}
+
+	return func(next ChangeHandlerFunc) ChangeHandlerFunc {
+		return func(ctx context.Context, c *Change) error {
+			start := time.Now()
+
+			err := next(ctx, c)
+
+			printf(
+				"type=%s account=%s duration=%s error=%v",
+				c.Type, c.accountID, time.Since(start), err,
+			)
+
+			return err
+		}
+	}
+}
+
+// SkipEcho returns middleware dropping echo events
+// of messages sent by the instagram account itself.
+func SkipEcho() Middleware {
+	return func(next MessagingHandlerFunc) MessagingHandlerFunc {
+		return func(ctx context.Context, m *Messaging) error {
+			if m.Type == WebhookEventTypeEcho {
+				return nil
+			}
+
+			return next(ctx, m)
+		}
+	}
+}
+
+// AllowSenders returns middleware passing only events
+// sent by one of the given instagram user ids.
+func AllowSenders(senderIDs ...string) Middleware {
+	allowed := toSet(senderIDs)
+
+	return func(next MessagingHandlerFunc) MessagingHandlerFunc {
+		return func(ctx context.Context, m *Messaging) error {
+			if m.Sender == nil {
+				return nil
+			}
+
+			if _, ok := allowed[m.Sender.ID]; !ok {
+				return nil
+			}
+
+			return next(ctx, m)
+		}
+	}
+}
+
+// DenySenders returns middleware dropping events
+// sent by any of the given instagram user ids.
+func DenySenders(senderIDs ...string) Middleware {
+	denied := toSet(senderIDs)
+
+	return func(next MessagingHandlerFunc) MessagingHandlerFunc {
+		return func(ctx context.Context, m *Messaging) error {
+			if m.Sender != nil {
+				if _, ok := denied[m.Sender.ID]; ok {
+					return nil
+				}
+			}
+
+			return next(ctx, m)
+		}
+	}
+}
+
+func toSet(values []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(values))
+	for _, v := range values {
+		set[v] = struct{}{}
+	}
+
+	return set
+}
@@ -0,0 +1,187 @@
+package instabot
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddlewareOrder(t *testing.T) {
+	var calls []string
+
+	trace := func(name string) Middleware {
+		return func(next MessagingHandlerFunc) MessagingHandlerFunc {
+			return func(ctx context.Context, m *Messaging) error {
+				calls = append(calls, name+":before")
+				err := next(ctx, m)
+				calls = append(calls, name+":after")
+
+				return err
+			}
+		}
+	}
+
+	d := NewDispatcher()
+	d.Use(trace("first"), trace("second"))
+	d.Use(trace("third"))
+	d.Handle(WebhookEventTypeTextMessage, func(ctx context.Context, m *Messaging) error {
+		calls = append(calls, "handler")
+
+		return nil
+	})
+
+	err := d.HandleMessaging(context.Background(), &Messaging{Type: WebhookEventTypeTextMessage})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"first:before",
+		"second:before",
+		"third:before",
+		"handler",
+		"third:after",
+		"second:after",
+		"first:after",
+	}, calls)
+}
+
+func TestMiddlewares(t *testing.T) {
+	testCases := []struct {
+		name       string
+		middleware Middleware
+		messaging  *Messaging
+		handlerErr error
+		panic      bool
+		wantCalled bool
+		wantErr    error
+	}{
+		{
+			name:       "recover should turn panic into error",
+			middleware: Recover(),
+			messaging:  &Messaging{Type: WebhookEventTypeTextMessage},
+			panic:      true,
+			wantCalled: true,
+			wantErr:    ErrHandlerPanic,
+		},
+		{
+			name:       "recover should pass handler error",
+			middleware: Recover(),
+			messaging:  &Messaging{Type: WebhookEventTypeTextMessage},
+			handlerErr: errors.New("handler error"),
+			wantCalled: true,
+			wantErr:    errors.New("handler error"),
+		},
+		{
+			name:       "skip echo should drop echo event",
+			middleware: SkipEcho(),
+			messaging:  &Messaging{Type: WebhookEventTypeEcho},
+			wantCalled: false,
+		},
+		{
+			name:       "skip echo should pass other event",
+			middleware: SkipEcho(),
+			messaging:  &Messaging{Type: WebhookEventTypeTextMessage},
+			wantCalled: true,
+		},
+		{
+			name:       "allow senders should pass allowed sender",
+			middleware: AllowSenders("<IGSID_1>", "<IGSID_2>"),
+			messaging:  &Messaging{Type: WebhookEventTypeTextMessage, Sender: &Sender{ID: "<IGSID_2>"}},
+			wantCalled: true,
+		},
+		{
+			name:       "allow senders should drop other sender",
+			middleware: AllowSenders("<IGSID_1>"),
+			messaging:  &Messaging{Type: WebhookEventTypeTextMessage, Sender: &Sender{ID: "<IGSID_2>"}},
+			wantCalled: false,
+		},
+		{
+			name:       "allow senders should drop event without sender",
+			middleware: AllowSenders("<IGSID_1>"),
+			messaging:  &Messaging{Type: WebhookEventTypeTextMessage},
+			wantCalled: false,
+		},
+		{
+			name:       "deny senders should drop denied sender",
+			middleware: DenySenders("<IGSID_1>"),
+			messaging:  &Messaging{Type: WebhookEventTypeTextMessage, Sender: &Sender{ID: "<IGSID_1>"}},
+			wantCalled: false,
+		},
+		{
+			name:       "deny senders should pass other sender",
+			middleware: DenySenders("<IGSID_1>"),
+			messaging:  &Messaging{Type: WebhookEventTypeTextMessage, Sender: &Sender{ID: "<IGSID_2>"}},
+			wantCalled: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			called := false
+
+			handler := tc.middleware(func(ctx context.Context, m *Messaging) error {
+				called = true
+
+				if tc.panic {
+					panic("boom")
+				}
+
+				return tc.handlerErr
+			})
+
+			err := handler(context.Background(), tc.messaging)
+			switch {
+			case tc.wantErr == ErrHandlerPanic:
+				assert.True(t, errors.Is(err, ErrHandlerPanic))
+			case tc.wantErr != nil:
+				assert.EqualError(t, err, tc.wantErr.Error())
+			default:
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, tc.wantCalled, called)
+		})
+	}
+}
+
+func TestLoggerMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+
+	handler := Logger(log.New(&buf, "", 0))(func(ctx context.Context, m *Messaging) error {
+		return errors.New("handler error")
+	})
+
+	err := handler(context.Background(), &Messaging{
+		Type:   WebhookEventTypeTextMessage,
+		Sender: &Sender{ID: "<IGSID>"},
+	})
+	assert.EqualError(t, err, "handler error")
+
+	line := buf.String()
+	assert.True(t, strings.HasPrefix(line, "type=text sender=<IGSID> duration="))
+	assert.True(t, strings.HasSuffix(line, "error=handler error\n"))
+}
+
+func TestLoggerMiddlewareStandardLogger(t *testing.T) {
+	var buf bytes.Buffer
+
+	flags := log.Flags()
+	log.SetOutput(&buf)
+	log.SetFlags(0)
+	defer func() {
+		log.SetOutput(os.Stderr)
+		log.SetFlags(flags)
+	}()
+
+	handler := Logger(nil)(func(ctx context.Context, m *Messaging) error {
+		return nil
+	})
+
+	assert.NoError(t, handler(context.Background(), &Messaging{Type: WebhookEventTypeTextMessage}))
+	assert.True(t, strings.HasPrefix(buf.String(), "type=text sender= duration="))
+}
@@ -0,0 +1,120 @@
+package instabot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// PrivateReplyWindow is how long after a comment is written
+// a private reply can be sent to it.
+const PrivateReplyWindow = 7 * 24 * time.Hour
+
+// graph api error codes of rejected private replies.
+const (
+	errorCodeCommentAlreadyReplied   int32 = 10900
+	errorCodeCommentCannotBeReplied  int32 = 10903
+	errorCodePermission              int32 = 10
+	errorSubCodeOutsideAllowedWindow int32 = 2534022
+)
+
+// PrivateReplyError defines error of a private reply
+// breaking instagram private reply rules.
+type PrivateReplyError struct {
+	CommentID string
+	Err       error
+	// Response holds api error response when the reply
+	// was rejected by instagram, nil when rejected by the client.
+	Response *ErrorResponse
+}
+
+// Error returns error message.
+func (e *PrivateReplyError) Error() string {
+	return "private reply to comment " + e.CommentID + ": " + e.Err.Error()
+}
+
+// Unwrap returns the broken rule, ErrCommentAlreadyReplied,
+// ErrPrivateReplyWindowExpired or ErrCommentCannotBeReplied.
+func (e *PrivateReplyError) Unwrap() error {
+	return e.Err
+}
+
+type privateReplyOptions struct {
+	commentTime time.Time
+}
+
+// PrivateReplyOption defines optional argument of a send private reply call.
+type PrivateReplyOption func(*privateReplyOptions)
+
+// WithCommentTime sets creation time of the replied comment,
+// so that the reply fails without calling the api once
+// PrivateReplyWindow is over.
+func WithCommentTime(t time.Time) PrivateReplyOption {
+	return func(o *privateReplyOptions) {
+		o.commentTime = t
+	}
+}
+
+// privateReplyRule returns the private reply rule broken
+// according to the api error response, nil when none is.
+func privateReplyRule(err error) (*ErrorResponse, error) {
+	var res *ErrorResponse
+	if !errors.As(err, &res) {
+		return nil, nil
+	}
+
+	switch {
+	case res.APIError.Code == errorCodeCommentAlreadyReplied:
+		return res, ErrCommentAlreadyReplied
+	case res.APIError.Code == errorCodePermission && res.APIError.SubCode == errorSubCodeOutsideAllowedWindow:
+		return res, ErrPrivateReplyWindowExpired
+	case res.APIError.Code == errorCodeCommentCannotBeReplied:
+		return res, ErrCommentCannotBeReplied
+	}
+
+	return nil, nil
+}
+
+// SendPrivateReply sends message to the author of comment commentID,
+// as a private reply. A comment can be replied privately only once,
+// within PrivateReplyWindow after it is written. Replies breaking these rules
+// fail with a PrivateReplyError, whether caught by the client or by instagram.
+// https://developers.facebook.com/docs/messenger-platform/instagram/features/private-replies
+func (c *Client) SendPrivateReply(ctx context.Context, commentID string, message Message, options ...PrivateReplyOption) (*SendMessageResponse, error) {
+	opts := &privateReplyOptions{}
+	for _, option := range options {
+		option(opts)
+	}
+
+	if !opts.commentTime.IsZero() && time.Since(opts.commentTime) > PrivateReplyWindow {
+		return nil, &PrivateReplyError{CommentID: commentID, Err: ErrPrivateReplyWindowExpired}
+	}
+
+	key := "comment:" + commentID
+
+	replied, err := c.privateReplies.MarkSeen(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if replied {
+		return nil, &PrivateReplyError{CommentID: commentID, Err: ErrCommentAlreadyReplied}
+	}
+
+	res, err := c.sendMessage(ctx, &Recipient{CommentID: commentID}, message, &sendOptions{})
+	if err != nil {
+		// comments instagram refuses to be replied stay recorded.
+		if response, rule := privateReplyRule(err); rule != nil {
+			return nil, &PrivateReplyError{CommentID: commentID, Err: rule, Response: response}
+		}
+
+		if fErr := c.privateReplies.Forget(ctx, key); fErr != nil {
+			return nil, fmt.Errorf("%w, forgetting comment %s: %v", err, commentID, fErr)
+		}
+
+		return nil, err
+	}
+
+	return res, nil
+}
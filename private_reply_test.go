@@ -0,0 +1,143 @@
+package instabot
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSendPrivateReply(t *testing.T) {
+	pageAccessToken := "page_access_token"
+
+	var calls int32
+	var fail int32
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, APIEndpointSendMessage, r.URL.Path)
+
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.JSONEq(t, `{
+			"recipient": {
+				"comment_id": "<COMMENT_ID>"
+			},
+			"message": {
+				"text": "Thanks for your comment!"
+			}
+		}`, string(body))
+
+		if atomic.LoadInt32(&fail) == 1 {
+			w.WriteHeader(500)
+			w.Write([]byte(`{"error": {"message": "error", "type": "OAuthException", "code": 2}}`))
+
+			return
+		}
+
+		w.WriteHeader(200)
+		w.Write([]byte(`{"recipient_id": "<IGSID>", "message_id": "<MID>"}`))
+	}))
+	defer mockServer.Close()
+
+	client, err := New(pageAccessToken, WithEndpointBase(mockServer.URL))
+	assert.NoError(t, err)
+
+	message := NewTextMessage("Thanks for your comment!")
+
+	// failed replies can be retried.
+	atomic.StoreInt32(&fail, 1)
+	_, err = client.SendPrivateReply(context.Background(), "<COMMENT_ID>", message)
+	assert.Error(t, err)
+
+	atomic.StoreInt32(&fail, 0)
+	res, err := client.SendPrivateReply(context.Background(), "<COMMENT_ID>", message, WithCommentTime(time.Now().Add(-time.Hour)))
+	assert.NoError(t, err)
+	assert.Equal(t, &SendMessageResponse{RecipientID: "<IGSID>", MessageID: "<MID>"}, res)
+
+	_, err = client.SendPrivateReply(context.Background(), "<COMMENT_ID>", message)
+	assert.True(t, errors.Is(err, ErrCommentAlreadyReplied))
+
+	var replyErr *PrivateReplyError
+	assert.True(t, errors.As(err, &replyErr))
+	assert.Equal(t, "<COMMENT_ID>", replyErr.CommentID)
+
+	_, err = client.SendPrivateReply(context.Background(), "<OLD_COMMENT_ID>", message, WithCommentTime(time.Now().Add(-PrivateReplyWindow-time.Minute)))
+	assert.True(t, errors.Is(err, ErrPrivateReplyWindowExpired))
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestSendPrivateReplyRejected(t *testing.T) {
+	testCases := []struct {
+		name           string
+		returnResponse string
+		wantErr        error
+		wantRecorded   bool
+	}{
+		{
+			name:           "already replied",
+			returnResponse: `{"error": {"message": "(#10900) Activity already replied to", "type": "OAuthException", "code": 10900}}`,
+			wantErr:        ErrCommentAlreadyReplied,
+			wantRecorded:   true,
+		},
+		{
+			name:           "comment cannot be replied",
+			returnResponse: `{"error": {"message": "(#10903) This activity can't be replied to", "type": "OAuthException", "code": 10903}}`,
+			wantErr:        ErrCommentCannotBeReplied,
+			wantRecorded:   true,
+		},
+		{
+			name:           "outside allowed window",
+			returnResponse: `{"error": {"message": "This message is sent outside of allowed window.", "type": "OAuthException", "code": 10, "error_subcode": 2534022}}`,
+			wantErr:        ErrPrivateReplyWindowExpired,
+			wantRecorded:   true,
+		},
+		{
+			name:           "other error",
+			returnResponse: `{"error": {"message": "error", "type": "OAuthException", "code": 2}}`,
+			wantRecorded:   false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(400)
+				w.Write([]byte(tc.returnResponse))
+			}))
+			defer mockServer.Close()
+
+			store := NewMemoryDedupStore(PrivateReplyWindow, 0)
+
+			client, err := New("page_access_token", WithEndpointBase(mockServer.URL), WithPrivateReplyStore(store))
+			assert.NoError(t, err)
+
+			_, err = client.SendPrivateReply(context.Background(), "<COMMENT_ID>", NewTextMessage("hi"))
+			assert.Error(t, err)
+
+			var replyErr *PrivateReplyError
+			if tc.wantErr != nil {
+				assert.True(t, errors.Is(err, tc.wantErr))
+				assert.True(t, errors.As(err, &replyErr))
+				assert.Equal(t, tc.wantErr, replyErr.Err)
+				assert.Equal(t, 400, replyErr.Response.StatusCode)
+			} else {
+				assert.False(t, errors.As(err, &replyErr))
+			}
+
+			recorded, _ := store.MarkSeen(context.Background(), "comment:<COMMENT_ID>")
+			assert.Equal(t, tc.wantRecorded, recorded)
+		})
+	}
+}
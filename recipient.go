@@ -2,6 +2,9 @@ package instabot
 
 // Recipient defines instagram user with instagram_user_id.
 // Recipient of a message or action.
+// A message sent as private reply to a comment is
+// addressed by CommentID instead of ID.
 type Recipient struct {
-	ID string `json:"id"`
+	ID        string `json:"id,omitempty"`
+	CommentID string `json:"comment_id,omitempty"`
 }
@@ -0,0 +1,147 @@
+package instabot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// RecordedDelivery defines a raw webhook delivery as received.
+type RecordedDelivery struct {
+	ReceivedAt time.Time   `json:"received_at"`
+	Header     http.Header `json:"header"`
+	Body       string      `json:"body"`
+}
+
+// Recorder appends raw webhook deliveries to a writer,
+// one JSON encoded RecordedDelivery per line.
+type Recorder struct {
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer
+	now    func() time.Time
+}
+
+// NewRecorder returns a new recorder writing to w.
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{
+		w:   w,
+		now: time.Now,
+	}
+}
+
+// NewFileRecorder returns a new recorder appending to the file at path,
+// creating it if necessary.
+func NewFileRecorder(path string) (*Recorder, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	r := NewRecorder(f)
+	r.closer = f
+
+	return r, nil
+}
+
+// Record appends a delivery with its headers and receive time.
+func (r *Recorder) Record(header http.Header, body []byte) error {
+	j, err := json.Marshal(&RecordedDelivery{
+		ReceivedAt: r.now().UTC(),
+		Header:     header,
+		Body:       string(body),
+	})
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, err = r.w.Write(append(j, '\n'))
+
+	return err
+}
+
+// Close closes the underlying file of a file recorder.
+func (r *Recorder) Close() error {
+	if r.closer == nil {
+		return nil
+	}
+
+	return r.closer.Close()
+}
+
+// WithRecorder records every delivery passing signature verification.
+// Recording failures do not fail the delivery, they are passed to the
+// function set with WithRecordErrorHandler or logged with the standard logger.
+func WithRecorder(recorder *Recorder) WebhookHandlerOption {
+	return func(h *WebhookHandler) error {
+		h.recorder = recorder
+
+		return nil
+	}
+}
+
+// WithRecordErrorHandler sets function called with errors recording deliveries.
+func WithRecordErrorHandler(errorHandler func(r *http.Request, err error)) WebhookHandlerOption {
+	return func(h *WebhookHandler) error {
+		h.recordErr = errorHandler
+
+		return nil
+	}
+}
+
+func (h *WebhookHandler) recordError(r *http.Request, err error) {
+	if h.recordErr != nil {
+		h.recordErr(r, err)
+
+		return
+	}
+
+	log.Printf("instabot: recording webhook delivery: %v", err)
+}
+
+// Replay decodes every delivery recorded in r and passes it to handler,
+// stopping at the first error.
+func Replay(ctx context.Context, r io.Reader, handler WebhookEventHandler) error {
+	dec := json.NewDecoder(r)
+
+	for n := 1; ; n++ {
+		delivery := RecordedDelivery{}
+		if err := dec.Decode(&delivery); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+
+			return fmt.Errorf("delivery %d: %w", n, err)
+		}
+
+		event := new(WebhookEvent)
+		if err := json.Unmarshal([]byte(delivery.Body), event); err != nil {
+			return fmt.Errorf("delivery %d: %w", n, err)
+		}
+
+		if err := handler.HandleWebhookEvent(ctx, event); err != nil {
+			return fmt.Errorf("delivery %d: %w", n, err)
+		}
+	}
+}
+
+// ReplayFile replays deliveries recorded in the file at path.
+func ReplayFile(ctx context.Context, path string, handler WebhookEventHandler) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	return Replay(ctx, f, handler)
+}
@@ -0,0 +1,174 @@
+package instabot
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	appSecret := "app_secret"
+	payload := `{
+		"object": "instagram",
+		"entry": [
+		  {
+			"id": "<IGID>",
+			"time": 1569262486134,
+			"messaging": [
+			  {
+				"sender": {
+				  "id": "<IGSID>"
+				},
+				"recipient": {
+				  "id": "<IGID>"
+				},
+				"timestamp": 1569262485349,
+				"message": {
+				  "mid": "<MESSAGE_ID>",
+				  "text": "<MESSAGE_CONTENT>"
+				}
+			  }
+			]
+		  }
+		]
+	}`
+
+	var buf bytes.Buffer
+	recorder := NewRecorder(&buf)
+	recorder.now = func() time.Time { return time.Unix(1569262486, 0) }
+
+	h, err := NewWebhookHandler(
+		appSecret,
+		WebhookEventHandlerFunc(func(ctx context.Context, event *WebhookEvent) error {
+			return nil
+		}),
+		WithRecorder(recorder),
+	)
+	assert.NoError(t, err)
+
+	deliver := func(signature string) int {
+		req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(payload))
+		req.Header.Set(HeaderHubSignature256, signature)
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, deliver(signPayload(appSecret, payload)))
+	assert.Equal(t, http.StatusForbidden, deliver("sha256=tampered"))
+	assert.Equal(t, http.StatusOK, deliver(signPayload(appSecret, payload)))
+
+	assert.Equal(t, 2, strings.Count(buf.String(), "\n"))
+	assert.Contains(t, buf.String(), `"received_at":"2019-09-23T18:14:46Z"`)
+
+	var got []*TextMessageEvent
+	d := NewDispatcher()
+	d.OnTextMessage(func(ctx context.Context, event *TextMessageEvent) error {
+		got = append(got, event)
+
+		return nil
+	})
+
+	err = Replay(context.Background(), bytes.NewReader(buf.Bytes()), d)
+	assert.NoError(t, err)
+	assert.Len(t, got, 2)
+	assert.Equal(t, "<MESSAGE_CONTENT>", got[0].Text)
+
+	errHandler := errors.New("handler error")
+	err = Replay(
+		context.Background(),
+		bytes.NewReader(buf.Bytes()),
+		WebhookEventHandlerFunc(func(ctx context.Context, event *WebhookEvent) error {
+			return errHandler
+		}),
+	)
+	assert.True(t, errors.Is(err, errHandler))
+	assert.EqualError(t, err, "delivery 1: handler error")
+}
+
+func TestFileRecorder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "instabot")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "deliveries.jsonl")
+	body := `{"object":"instagram","entry":[]}`
+
+	for i := 0; i < 2; i++ {
+		recorder, err := NewFileRecorder(path)
+		assert.NoError(t, err)
+
+		header := http.Header{}
+		header.Set(HeaderHubSignature256, "sha256=signature")
+		assert.NoError(t, recorder.Record(header, []byte(body)))
+		assert.NoError(t, recorder.Close())
+	}
+
+	count := 0
+	err = ReplayFile(
+		context.Background(),
+		path,
+		WebhookEventHandlerFunc(func(ctx context.Context, event *WebhookEvent) error {
+			assert.Equal(t, "instagram", event.Object)
+			count++
+
+			return nil
+		}),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
+
+type failingWriter struct {
+	err error
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	return 0, w.err
+}
+
+func TestRecordErrorHandler(t *testing.T) {
+	appSecret := "app_secret"
+	payload := `{"object": "instagram", "entry": []}`
+	errWrite := errors.New("disk full")
+
+	var got []error
+	var delivered int
+
+	h, err := NewWebhookHandler(
+		appSecret,
+		WebhookEventHandlerFunc(func(ctx context.Context, event *WebhookEvent) error {
+			delivered++
+
+			return nil
+		}),
+		WithRecorder(NewRecorder(&failingWriter{err: errWrite})),
+		WithRecordErrorHandler(func(r *http.Request, err error) {
+			got = append(got, err)
+		}),
+	)
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(payload))
+	req.Header.Set(HeaderHubSignature256, signPayload(appSecret, payload))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	// recording failure is reported without failing the delivery.
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 1, delivered)
+	assert.Equal(t, []error{errWrite}, got)
+}
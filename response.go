@@ -180,3 +180,210 @@ func decodeToGetUserProfileResponse(res *http.Response) (*GetUserProfileResponse
 
 	return &response, nil
 }
+
+// ThreadControlResponse defines handover protocol thread control api success response.
+type ThreadControlResponse struct {
+	Success bool `json:"success"`
+}
+
+func decodeToThreadControlResponse(res *http.Response) (*ThreadControlResponse, error) {
+	if err := checkErrorResponse(res); err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(res.Body)
+
+	response := ThreadControlResponse{}
+
+	if err := decoder.Decode(&response); err != nil {
+		if err == io.EOF {
+			return &response, nil
+		}
+
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// ThreadOwner defines app owning a conversation.
+type ThreadOwner struct {
+	AppID AppID `json:"app_id"`
+}
+
+// ThreadOwnerData holds thread owner.
+type ThreadOwnerData struct {
+	ThreadOwner ThreadOwner `json:"thread_owner"`
+}
+
+// GetThreadOwnerResponse defines get thread owner api success response.
+type GetThreadOwnerResponse struct {
+	Data []ThreadOwnerData `json:"data"`
+}
+
+func decodeToGetThreadOwnerResponse(res *http.Response) (*GetThreadOwnerResponse, error) {
+	if err := checkErrorResponse(res); err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(res.Body)
+
+	response := GetThreadOwnerResponse{}
+
+	if err := decoder.Decode(&response); err != nil {
+		if err == io.EOF {
+			return &response, nil
+		}
+
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// SubscriptionResponse defines webhook subscription api success response.
+type SubscriptionResponse struct {
+	Success bool `json:"success"`
+}
+
+func decodeToSubscriptionResponse(res *http.Response) (*SubscriptionResponse, error) {
+	if err := checkErrorResponse(res); err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(res.Body)
+
+	response := SubscriptionResponse{}
+
+	if err := decoder.Decode(&response); err != nil {
+		if err == io.EOF {
+			return &response, nil
+		}
+
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// SubscribedApp defines app subscribed to a page webhooks.
+type SubscribedApp struct {
+	ID               string         `json:"id"`
+	Name             string         `json:"name"`
+	Link             string         `json:"link"`
+	SubscribedFields []WebhookField `json:"subscribed_fields"`
+}
+
+// GetSubscribedAppsResponse defines get page subscribed apps api success response.
+type GetSubscribedAppsResponse struct {
+	Data []SubscribedApp `json:"data"`
+}
+
+func decodeToGetSubscribedAppsResponse(res *http.Response) (*GetSubscribedAppsResponse, error) {
+	if err := checkErrorResponse(res); err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(res.Body)
+
+	response := GetSubscribedAppsResponse{}
+
+	if err := decoder.Decode(&response); err != nil {
+		if err == io.EOF {
+			return &response, nil
+		}
+
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// AppSubscriptionField defines field of an app webhook subscription.
+type AppSubscriptionField struct {
+	Name    WebhookField `json:"name"`
+	Version string       `json:"version"`
+}
+
+// AppSubscription defines app webhook subscription of an object.
+type AppSubscription struct {
+	Object      string                 `json:"object"`
+	CallbackURL string                 `json:"callback_url"`
+	Active      bool                   `json:"active"`
+	Fields      []AppSubscriptionField `json:"fields"`
+}
+
+// GetAppSubscriptionsResponse defines get app subscriptions api success response.
+type GetAppSubscriptionsResponse struct {
+	Data []AppSubscription `json:"data"`
+}
+
+func decodeToGetAppSubscriptionsResponse(res *http.Response) (*GetAppSubscriptionsResponse, error) {
+	if err := checkErrorResponse(res); err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(res.Body)
+
+	response := GetAppSubscriptionsResponse{}
+
+	if err := decoder.Decode(&response); err != nil {
+		if err == io.EOF {
+			return &response, nil
+		}
+
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// SenderActionResponse defines send sender action api success response.
+type SenderActionResponse struct {
+	RecipientID string `json:"recipient_id"`
+}
+
+func decodeToSenderActionResponse(res *http.Response) (*SenderActionResponse, error) {
+	if err := checkErrorResponse(res); err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(res.Body)
+
+	response := SenderActionResponse{}
+
+	if err := decoder.Decode(&response); err != nil {
+		if err == io.EOF {
+			return &response, nil
+		}
+
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// UploadAttachmentResponse defines attachment upload api success response.
+type UploadAttachmentResponse struct {
+	AttachmentID string `json:"attachment_id"`
+}
+
+func decodeToUploadAttachmentResponse(res *http.Response) (*UploadAttachmentResponse, error) {
+	if err := checkErrorResponse(res); err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(res.Body)
+
+	response := UploadAttachmentResponse{}
+
+	if err := decoder.Decode(&response); err != nil {
+		if err == io.EOF {
+			return &response, nil
+		}
+
+		return nil, err
+	}
+
+	return &response, nil
+}
@@ -7,25 +7,144 @@ import (
 	"io"
 )
 
-func encodeSendMessageJSON(w io.Writer, recipeint string, message Message) error {
+// MessagingType defines purpose of a sent message.
+type MessagingType string
+
+// all messaging type.
+// https://developers.facebook.com/docs/messenger-platform/send-messages#messaging_types
+const (
+	MessagingTypeResponse   MessagingType = MessagingType("RESPONSE")
+	MessagingTypeUpdate     MessagingType = MessagingType("UPDATE")
+	MessagingTypeMessageTag MessagingType = MessagingType("MESSAGE_TAG")
+)
+
+// MessageTag defines tag allowing to send message
+// outside of the 24 hours standard messaging window.
+type MessageTag string
+
+// all message tag available on instagram.
+// https://developers.facebook.com/docs/messenger-platform/instagram/features/send-message#human-agent
+const (
+	TagHumanAgent MessageTag = MessageTag("HUMAN_AGENT")
+)
+
+// NotificationType defines push notification type of a sent message.
+type NotificationType string
+
+// all notification type.
+const (
+	NotificationTypeRegular    NotificationType = NotificationType("REGULAR")
+	NotificationTypeSilentPush NotificationType = NotificationType("SILENT_PUSH")
+	NotificationTypeNoPush     NotificationType = NotificationType("NO_PUSH")
+)
+
+type sendOptions struct {
+	messagingType    MessagingType
+	tag              MessageTag
+	notificationType NotificationType
+	replyToMID       string
+}
+
+// sendReplyTo defines message a sent message replies to.
+type sendReplyTo struct {
+	MID string `json:"mid"`
+}
+
+// SendOption defines optional argument of a send message call.
+type SendOption func(*sendOptions)
+
+// WithMessagingType sets messaging type of the sent message.
+func WithMessagingType(messagingType MessagingType) SendOption {
+	return func(o *sendOptions) {
+		o.messagingType = messagingType
+	}
+}
+
+// WithMessageTag sets tag of the sent message,
+// messaging type defaults to MessagingTypeMessageTag.
+func WithMessageTag(tag MessageTag) SendOption {
+	return func(o *sendOptions) {
+		o.tag = tag
+	}
+}
+
+// WithNotificationType sets push notification type of the sent message.
+func WithNotificationType(notificationType NotificationType) SendOption {
+	return func(o *sendOptions) {
+		o.notificationType = notificationType
+	}
+}
+
+// WithReplyTo sends the message as a reply to the message mid,
+// quoting it in the conversation. Any message type can be a reply.
+func WithReplyTo(mid string) SendOption {
+	return func(o *sendOptions) {
+		o.replyToMID = mid
+	}
+}
+
+func newSendOptions(options []SendOption) (*sendOptions, error) {
+	opts := &sendOptions{}
+	for _, option := range options {
+		option(opts)
+	}
+
+	if opts.tag != "" {
+		if opts.messagingType == "" {
+			opts.messagingType = MessagingTypeMessageTag
+		}
+
+		if opts.messagingType != MessagingTypeMessageTag {
+			return nil, ErrInvalidMessagingType
+		}
+	}
+
+	if opts.messagingType == MessagingTypeMessageTag && opts.tag == "" {
+		return nil, ErrMissingMessageTag
+	}
+
+	return opts, nil
+}
+
+func encodeSendMessageJSON(w io.Writer, recipient *Recipient, message Message, opts *sendOptions) error {
+	var replyTo *sendReplyTo
+	if opts.replyToMID != "" {
+		replyTo = &sendReplyTo{MID: opts.replyToMID}
+	}
+
 	enc := json.NewEncoder(w)
 
 	return enc.Encode(&struct {
-		Recipient *Recipient `json:"recipient"`
-		Message   Message    `json:"message"`
+		Recipient        *Recipient       `json:"recipient"`
+		MessagingType    MessagingType    `json:"messaging_type,omitempty"`
+		Tag              MessageTag       `json:"tag,omitempty"`
+		NotificationType NotificationType `json:"notification_type,omitempty"`
+		ReplyTo          *sendReplyTo     `json:"reply_to,omitempty"`
+		Message          Message          `json:"message"`
 	}{
-		Recipient: &Recipient{
-			ID: recipeint,
-		},
-		Message: message,
+		Recipient:        recipient,
+		MessagingType:    opts.messagingType,
+		Tag:              opts.tag,
+		NotificationType: opts.notificationType,
+		ReplyTo:          replyTo,
+		Message:          message,
 	})
 }
 
 // SendMessage sends message by calling instagram api.
 // https://developers.facebook.com/docs/messenger-platform/instagram/features/send-message#send-api
-func (c *Client) SendMessage(ctx context.Context, recipient string, message Message) (*SendMessageResponse, error) {
+func (c *Client) SendMessage(ctx context.Context, recipient string, message Message, options ...SendOption) (*SendMessageResponse, error) {
+	opts, err := newSendOptions(options)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.sendMessage(ctx, &Recipient{ID: recipient}, message, opts)
+}
+
+func (c *Client) sendMessage(ctx context.Context, recipient *Recipient, message Message, opts *sendOptions) (*SendMessageResponse, error) {
 	var buf bytes.Buffer
-	if err := encodeSendMessageJSON(&buf, recipient, message); err != nil {
+	if err := encodeSendMessageJSON(&buf, recipient, message, opts); err != nil {
 		return nil, err
 	}
 
@@ -18,6 +18,7 @@ func TestSendMessage(t *testing.T) {
 	type args struct {
 		ctx     context.Context
 		message Message
+		options []SendOption
 	}
 
 	type fields struct {
@@ -410,6 +411,140 @@ func TestSendMessage(t *testing.T) {
 				},
 			}
 		},
+		"text message with human agent tag": func(t *testing.T) test {
+			args := args{
+				ctx:     context.Background(),
+				message: NewTextMessage("hello"),
+				options: []SendOption{WithMessageTag(TagHumanAgent)},
+			}
+
+			fields := fields{
+				wantRequestBody: fmt.Sprintf(`{
+					"recipient": {
+						"id": "%s"
+					},
+					"messaging_type": "MESSAGE_TAG",
+					"tag": "HUMAN_AGENT",
+					"message": {
+						"text": "hello"
+					}
+				}`, recipient),
+				returnResponse: fmt.Sprintf(`{
+					"recipient_id": "%s",
+					"message_id": "test_message_id"
+				}`, recipient),
+				returnResponseCode: 200,
+			}
+
+			return test{
+				args:   args,
+				fields: fields,
+				want: &SendMessageResponse{
+					RecipientID: recipient,
+					MessageID:   "test_message_id",
+				},
+			}
+		},
+		"text message with messaging and notification type": func(t *testing.T) test {
+			args := args{
+				ctx:     context.Background(),
+				message: NewTextMessage("hello"),
+				options: []SendOption{
+					WithMessagingType(MessagingTypeResponse),
+					WithNotificationType(NotificationTypeSilentPush),
+				},
+			}
+
+			fields := fields{
+				wantRequestBody: fmt.Sprintf(`{
+					"recipient": {
+						"id": "%s"
+					},
+					"messaging_type": "RESPONSE",
+					"notification_type": "SILENT_PUSH",
+					"message": {
+						"text": "hello"
+					}
+				}`, recipient),
+				returnResponse: fmt.Sprintf(`{
+					"recipient_id": "%s",
+					"message_id": "test_message_id"
+				}`, recipient),
+				returnResponseCode: 200,
+			}
+
+			return test{
+				args:   args,
+				fields: fields,
+				want: &SendMessageResponse{
+					RecipientID: recipient,
+					MessageID:   "test_message_id",
+				},
+			}
+		},
+		"image message as reply": func(t *testing.T) test {
+			args := args{
+				ctx:     context.Background(),
+				message: NewImageMessage("https://example.com/image.png"),
+				options: []SendOption{WithReplyTo("<MESSAGE_ID>")},
+			}
+
+			fields := fields{
+				wantRequestBody: fmt.Sprintf(`{
+					"recipient": {
+						"id": "%s"
+					},
+					"reply_to": {
+						"mid": "<MESSAGE_ID>"
+					},
+					"message": {
+						"attachment": {
+							"type": "image",
+							"payload": {
+								"url": "https://example.com/image.png"
+							}
+						}
+					}
+				}`, recipient),
+				returnResponse: fmt.Sprintf(`{
+					"recipient_id": "%s",
+					"message_id": "test_message_id"
+				}`, recipient),
+				returnResponseCode: 200,
+			}
+
+			return test{
+				args:   args,
+				fields: fields,
+				want: &SendMessageResponse{
+					RecipientID: recipient,
+					MessageID:   "test_message_id",
+				},
+			}
+		},
+		"tagged message with update messaging type- error": func(t *testing.T) test {
+			return test{
+				args: args{
+					ctx:     context.Background(),
+					message: NewTextMessage("hello"),
+					options: []SendOption{
+						WithMessagingType(MessagingTypeUpdate),
+						WithMessageTag(TagHumanAgent),
+					},
+				},
+				wantErr: ErrInvalidMessagingType,
+			}
+		},
+		"message tag messaging type without tag- error": func(t *testing.T) test {
+			return test{
+				args: args{
+					ctx:     context.Background(),
+					message: NewTextMessage("hello"),
+					options: []SendOption{WithMessagingType(MessagingTypeMessageTag)},
+				},
+				wantErr: ErrMissingMessageTag,
+			}
+		},
 	}
 
 	var currentTest string
@@ -440,7 +575,7 @@ func TestSendMessage(t *testing.T) {
 			client, err := New(pageAccessToken, WithEndpointBase(mockServer.URL))
 			assert.NoError(t, err)
 
-			res, err := client.SendMessage(tt.args.ctx, recipient, tt.args.message)
+			res, err := client.SendMessage(tt.args.ctx, recipient, tt.args.message, tt.args.options...)
 			if tt.wantErr != nil {
 				assert.EqualError(t, tt.wantErr, err.Error())
 			} else {
@@ -0,0 +1,148 @@
+package instabot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// SenderAction defines action shown to the user in the conversation.
+type SenderAction string
+
+// all sender action.
+// https://developers.facebook.com/docs/messenger-platform/instagram/features/send-message#sender-actions
+const (
+	SenderActionTypingOn  SenderAction = SenderAction("typing_on")
+	SenderActionTypingOff SenderAction = SenderAction("typing_off")
+	SenderActionMarkSeen  SenderAction = SenderAction("mark_seen")
+	SenderActionReact     SenderAction = SenderAction("react")
+	SenderActionUnreact   SenderAction = SenderAction("unreact")
+)
+
+// ReactionType defines reaction sent to a message.
+type ReactionType string
+
+// all reaction type.
+// https://developers.facebook.com/docs/messenger-platform/instagram/features/send-message#reactions
+const (
+	ReactionLove ReactionType = ReactionType("love")
+)
+
+// senderActionPayload defines payload of react and unreact sender actions.
+type senderActionPayload struct {
+	MessageID string       `json:"message_id"`
+	Reaction  ReactionType `json:"reaction,omitempty"`
+}
+
+// typingRefreshInterval is the interval typing indicator is sent again
+// while showing typing, as instagram turns it off after 20 seconds.
+var typingRefreshInterval = 15 * time.Second
+
+// typingOffTimeout bounds turning typing indicator off, which is sent
+// independently of the caller context so that it is turned off
+// even when the context is cancelled while fn runs.
+var typingOffTimeout = 5 * time.Second
+
+func encodeSenderActionJSON(w io.Writer, recipient string, action SenderAction, payload *senderActionPayload) error {
+	enc := json.NewEncoder(w)
+
+	return enc.Encode(&struct {
+		Recipient    *Recipient           `json:"recipient"`
+		SenderAction SenderAction         `json:"sender_action"`
+		Payload      *senderActionPayload `json:"payload,omitempty"`
+	}{
+		Recipient: &Recipient{
+			ID: recipient,
+		},
+		SenderAction: action,
+		Payload:      payload,
+	})
+}
+
+// SendSenderAction sends sender action, like typing indicator or mark seen, to the recipient.
+// https://developers.facebook.com/docs/messenger-platform/instagram/features/send-message#sender-actions
+func (c *Client) SendSenderAction(ctx context.Context, recipient string, action SenderAction) (*SenderActionResponse, error) {
+	return c.sendSenderAction(ctx, recipient, action, nil)
+}
+
+// SendReaction reacts to the message mid of the recipient.
+// https://developers.facebook.com/docs/messenger-platform/instagram/features/send-message#reactions
+func (c *Client) SendReaction(ctx context.Context, recipient string, mid string, reaction ReactionType) (*SenderActionResponse, error) {
+	return c.sendSenderAction(ctx, recipient, SenderActionReact, &senderActionPayload{
+		MessageID: mid,
+		Reaction:  reaction,
+	})
+}
+
+// RemoveReaction removes reaction from the message mid of the recipient.
+func (c *Client) RemoveReaction(ctx context.Context, recipient string, mid string) (*SenderActionResponse, error) {
+	return c.sendSenderAction(ctx, recipient, SenderActionUnreact, &senderActionPayload{
+		MessageID: mid,
+	})
+}
+
+func (c *Client) sendSenderAction(ctx context.Context, recipient string, action SenderAction, payload *senderActionPayload) (*SenderActionResponse, error) {
+	var buf bytes.Buffer
+	if err := encodeSenderActionJSON(&buf, recipient, action, payload); err != nil {
+		return nil, err
+	}
+
+	res, err := c.post(ctx, APIEndpointSendMessage, &buf)
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	return decodeToSenderActionResponse(res)
+}
+
+// ShowTyping shows typing indicator to the recipient while fn runs,
+// and turns it off once fn returns. The indicator is refreshed while
+// fn runs longer than instagram shows it.
+// Typing indicator failures do not prevent fn from running, the error
+// of fn is returned if any, otherwise the first typing indicator error.
+func (c *Client) ShowTyping(ctx context.Context, recipient string, fn func(ctx context.Context) error) error {
+	_, typingErr := c.SendSenderAction(ctx, recipient, SenderActionTypingOn)
+
+	done := make(chan struct{})
+	refreshed := make(chan struct{})
+
+	go func() {
+		defer close(refreshed)
+
+		ticker := time.NewTicker(typingRefreshInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.SendSenderAction(ctx, recipient, SenderActionTypingOn)
+			}
+		}
+	}()
+
+	err := fn(ctx)
+
+	close(done)
+	<-refreshed
+
+	offCtx, cancel := context.WithTimeout(context.Background(), typingOffTimeout)
+	defer cancel()
+
+	if _, offErr := c.SendSenderAction(offCtx, recipient, SenderActionTypingOff); typingErr == nil {
+		typingErr = offErr
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return typingErr
+}
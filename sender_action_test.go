@@ -0,0 +1,291 @@
+package instabot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSendSenderAction(t *testing.T) {
+	pageAccessToken := "page_access_token"
+	recipient := "test_recipient"
+
+	testCases := []struct {
+		name               string
+		action             SenderAction
+		returnResponse     string
+		returnResponseCode int
+		want               *SenderActionResponse
+		wantErr            error
+	}{
+		{
+			name:               "typing on",
+			action:             SenderActionTypingOn,
+			returnResponse:     `{"recipient_id": "test_recipient"}`,
+			returnResponseCode: 200,
+			want:               &SenderActionResponse{RecipientID: recipient},
+		},
+		{
+			name:               "mark seen",
+			action:             SenderActionMarkSeen,
+			returnResponse:     `{"recipient_id": "test_recipient"}`,
+			returnResponseCode: 200,
+			want:               &SenderActionResponse{RecipientID: recipient},
+		},
+		{
+			name:   "typing off error",
+			action: SenderActionTypingOff,
+			returnResponse: `{
+				"error": {
+					"message": "error",
+					"type": "OAuthException",
+					"code": 100,
+					"fbtrace_id": "fbtrace_id"
+				}
+			}`,
+			returnResponseCode: 400,
+			wantErr: &ErrorResponse{
+				StatusCode: 400,
+				APIError: APIError{
+					Message:   "error",
+					Type:      "OAuthException",
+					Code:      100,
+					FbTraceID: "fbtrace_id",
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+
+				assert.Equal(t, APIEndpointSendMessage, r.URL.Path)
+
+				body, err := ioutil.ReadAll(r.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				assert.JSONEq(t, `{
+					"recipient": {
+						"id": "test_recipient"
+					},
+					"sender_action": "`+string(tc.action)+`"
+				}`, string(body))
+
+				w.WriteHeader(tc.returnResponseCode)
+				w.Write([]byte(tc.returnResponse))
+			}))
+			defer mockServer.Close()
+
+			client, err := New(pageAccessToken, WithEndpointBase(mockServer.URL))
+			assert.NoError(t, err)
+
+			res, err := client.SendSenderAction(context.Background(), recipient, tc.action)
+			if tc.wantErr != nil {
+				assert.EqualError(t, tc.wantErr, err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, tc.want, res)
+		})
+	}
+}
+
+func TestShowTyping(t *testing.T) {
+	errCallback := errors.New("callback error")
+
+	defaultInterval := typingRefreshInterval
+	defer func() { typingRefreshInterval = defaultInterval }()
+
+	testCases := []struct {
+		name     string
+		interval time.Duration
+		sleep    time.Duration
+		fnErr    error
+		wantErr  error
+		wantMore bool
+	}{
+		{
+			name:     "it should turn typing on and off around callback",
+			interval: time.Minute,
+		},
+		{
+			name:     "it should refresh typing while callback runs",
+			interval: 10 * time.Millisecond,
+			sleep:    50 * time.Millisecond,
+			wantMore: true,
+		},
+		{
+			name:     "it should return callback error",
+			interval: time.Minute,
+			fnErr:    errCallback,
+			wantErr:  errCallback,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			typingRefreshInterval = tc.interval
+
+			var mu sync.Mutex
+			var actions []SenderAction
+
+			mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body := struct {
+					SenderAction SenderAction `json:"sender_action"`
+				}{}
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+				mu.Lock()
+				actions = append(actions, body.SenderAction)
+				mu.Unlock()
+
+				w.Write([]byte(`{"recipient_id": "test_recipient"}`))
+			}))
+			defer mockServer.Close()
+
+			client, err := New("page_access_token", WithEndpointBase(mockServer.URL))
+			assert.NoError(t, err)
+
+			called := false
+			err = client.ShowTyping(context.Background(), "test_recipient", func(ctx context.Context) error {
+				called = true
+				time.Sleep(tc.sleep)
+
+				return tc.fnErr
+			})
+			assert.Equal(t, tc.wantErr, err)
+			assert.True(t, called)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			assert.Equal(t, SenderActionTypingOn, actions[0])
+			assert.Equal(t, SenderActionTypingOff, actions[len(actions)-1])
+
+			if tc.wantMore {
+				assert.Greater(t, len(actions), 2)
+			} else {
+				assert.Len(t, actions, 2)
+			}
+		})
+	}
+}
+
+func TestReaction(t *testing.T) {
+	pageAccessToken := "page_access_token"
+	recipient := "test_recipient"
+
+	testCases := []struct {
+		name            string
+		call            func(ctx context.Context, client *Client) (*SenderActionResponse, error)
+		wantRequestBody string
+	}{
+		{
+			name: "send reaction",
+			call: func(ctx context.Context, client *Client) (*SenderActionResponse, error) {
+				return client.SendReaction(ctx, recipient, "<MESSAGE_ID>", ReactionLove)
+			},
+			wantRequestBody: `{
+				"recipient": {
+					"id": "test_recipient"
+				},
+				"sender_action": "react",
+				"payload": {
+					"message_id": "<MESSAGE_ID>",
+					"reaction": "love"
+				}
+			}`,
+		},
+		{
+			name: "remove reaction",
+			call: func(ctx context.Context, client *Client) (*SenderActionResponse, error) {
+				return client.RemoveReaction(ctx, recipient, "<MESSAGE_ID>")
+			},
+			wantRequestBody: `{
+				"recipient": {
+					"id": "test_recipient"
+				},
+				"sender_action": "unreact",
+				"payload": {
+					"message_id": "<MESSAGE_ID>"
+				}
+			}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+
+				assert.Equal(t, APIEndpointSendMessage, r.URL.Path)
+
+				body, err := ioutil.ReadAll(r.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				assert.JSONEq(t, tc.wantRequestBody, string(body))
+
+				w.Write([]byte(`{"recipient_id": "test_recipient"}`))
+			}))
+			defer mockServer.Close()
+
+			client, err := New(pageAccessToken, WithEndpointBase(mockServer.URL))
+			assert.NoError(t, err)
+
+			res, err := tc.call(context.Background(), client)
+			assert.NoError(t, err)
+			assert.Equal(t, &SenderActionResponse{RecipientID: recipient}, res)
+		})
+	}
+}
+
+func TestShowTypingCancelled(t *testing.T) {
+	var mu sync.Mutex
+	var actions []SenderAction
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := struct {
+			SenderAction SenderAction `json:"sender_action"`
+		}{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		mu.Lock()
+		actions = append(actions, body.SenderAction)
+		mu.Unlock()
+
+		w.Write([]byte(`{"recipient_id": "test_recipient"}`))
+	}))
+	defer mockServer.Close()
+
+	client, err := New("page_access_token", WithEndpointBase(mockServer.URL))
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	err = client.ShowTyping(ctx, "test_recipient", func(ctx context.Context) error {
+		cancel()
+
+		return ctx.Err()
+	})
+	assert.Equal(t, context.Canceled, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	assert.Equal(t, []SenderAction{SenderActionTypingOn, SenderActionTypingOff}, actions)
+}
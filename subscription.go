@@ -0,0 +1,151 @@
+package instabot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// WebhookField defines instagram webhook field an app subscribes to.
+type WebhookField string
+
+// all instagram webhook fields.
+// https://developers.facebook.com/docs/messenger-platform/instagram/features/webhook#webhook-fields
+const (
+	WebhookFieldMessages           WebhookField = WebhookField("messages")
+	WebhookFieldMessagingPostbacks WebhookField = WebhookField("messaging_postbacks")
+	WebhookFieldMessagingSeen      WebhookField = WebhookField("messaging_seen")
+	WebhookFieldMessagingReferral  WebhookField = WebhookField("messaging_referral")
+	WebhookFieldMessagingHandover  WebhookField = WebhookField("messaging_handover")
+	WebhookFieldMessageReactions   WebhookField = WebhookField("message_reactions")
+	WebhookFieldStandby            WebhookField = WebhookField("standby")
+	WebhookFieldComments           WebhookField = WebhookField("comments")
+	WebhookFieldLiveComments       WebhookField = WebhookField("live_comments")
+	WebhookFieldMentions           WebhookField = WebhookField("mentions")
+	WebhookFieldStoryInsights      WebhookField = WebhookField("story_insights")
+)
+
+func encodeSubscribeAppJSON(w io.Writer, fields []WebhookField) error {
+	enc := json.NewEncoder(w)
+
+	return enc.Encode(&struct {
+		SubscribedFields []WebhookField `json:"subscribed_fields"`
+	}{
+		SubscribedFields: fields,
+	})
+}
+
+// SubscribeApp subscribes the app to webhooks of the facebook page
+// linked to the instagram account, replacing previously subscribed fields.
+// https://developers.facebook.com/docs/graph-api/reference/page/subscribed_apps
+func (c *Client) SubscribeApp(ctx context.Context, pageID string, fields []WebhookField) (*SubscriptionResponse, error) {
+	var buf bytes.Buffer
+	if err := encodeSubscribeAppJSON(&buf, fields); err != nil {
+		return nil, err
+	}
+
+	res, err := c.post(ctx, GetAPIEndpointSubscribedApps(pageID), &buf)
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	return decodeToSubscriptionResponse(res)
+}
+
+// UnsubscribeApp unsubscribes the app from webhooks of the page.
+func (c *Client) UnsubscribeApp(ctx context.Context, pageID string) (*SubscriptionResponse, error) {
+	res, err := c.delete(ctx, GetAPIEndpointSubscribedApps(pageID), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	return decodeToSubscriptionResponse(res)
+}
+
+// GetSubscribedApps fetches apps subscribed to webhooks of the page
+// along with their subscribed fields.
+func (c *Client) GetSubscribedApps(ctx context.Context, pageID string) (*GetSubscribedAppsResponse, error) {
+	res, err := c.get(ctx, GetAPIEndpointSubscribedApps(pageID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	return decodeToGetSubscribedAppsResponse(res)
+}
+
+func encodeSetAppSubscriptionJSON(w io.Writer, callbackURL string, verifyToken string, fields []WebhookField) error {
+	enc := json.NewEncoder(w)
+
+	return enc.Encode(&struct {
+		Object        string         `json:"object"`
+		CallbackURL   string         `json:"callback_url"`
+		VerifyToken   string         `json:"verify_token"`
+		Fields        []WebhookField `json:"fields"`
+		IncludeValues bool           `json:"include_values"`
+	}{
+		Object:        Platform,
+		CallbackURL:   callbackURL,
+		VerifyToken:   verifyToken,
+		Fields:        fields,
+		IncludeValues: true,
+	})
+}
+
+// SetAppSubscription sets the app instagram webhook callback url,
+// verify token and fields, creating the subscription if missing.
+// Facebook verifies the callback url with the verify token before accepting it.
+// Requires app credentials, see WithAppCredentials.
+// https://developers.facebook.com/docs/graph-api/reference/app/subscriptions
+func (c *Client) SetAppSubscription(ctx context.Context, callbackURL string, verifyToken string, fields []WebhookField) (*SubscriptionResponse, error) {
+	var buf bytes.Buffer
+	if err := encodeSetAppSubscriptionJSON(&buf, callbackURL, verifyToken, fields); err != nil {
+		return nil, err
+	}
+
+	res, err := c.appRequest(ctx, http.MethodPost, GetAPIEndpointAppSubscriptions(c.appID), &buf, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	return decodeToSubscriptionResponse(res)
+}
+
+// GetAppSubscriptions fetches webhook subscriptions of the app.
+// Requires app credentials, see WithAppCredentials.
+func (c *Client) GetAppSubscriptions(ctx context.Context) (*GetAppSubscriptionsResponse, error) {
+	res, err := c.appRequest(ctx, http.MethodGet, GetAPIEndpointAppSubscriptions(c.appID), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	return decodeToGetAppSubscriptionsResponse(res)
+}
+
+// DeleteAppSubscription deletes the app instagram webhook subscription.
+// Requires app credentials, see WithAppCredentials.
+func (c *Client) DeleteAppSubscription(ctx context.Context) (*SubscriptionResponse, error) {
+	query := url.Values{}
+	query.Add("object", Platform)
+
+	res, err := c.appRequest(ctx, http.MethodDelete, GetAPIEndpointAppSubscriptions(c.appID), nil, query)
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	return decodeToSubscriptionResponse(res)
+}
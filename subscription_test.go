@@ -0,0 +1,261 @@
+package instabot
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscription(t *testing.T) {
+	pageAccessToken := "page_access_token"
+	appAccessToken := "app_id|app_secret"
+	pageID := "page_id"
+
+	type fields struct {
+		wantMethod         string
+		wantEndpoint       string
+		wantQuery          url.Values
+		wantRequestBody    string
+		returnResponse     string
+		returnResponseCode int
+	}
+
+	type test struct {
+		call    func(ctx context.Context, client *Client) (interface{}, error)
+		fields  fields
+		want    interface{}
+		wantErr error
+	}
+
+	tests := map[string]func(t *testing.T) test{
+		"subscribe app success": func(t *testing.T) test {
+			return test{
+				call: func(ctx context.Context, client *Client) (interface{}, error) {
+					return client.SubscribeApp(ctx, pageID, []WebhookField{
+						WebhookFieldMessages,
+						WebhookFieldMessagingPostbacks,
+					})
+				},
+				fields: fields{
+					wantMethod:         http.MethodPost,
+					wantEndpoint:       GetAPIEndpointSubscribedApps(pageID),
+					wantQuery:          url.Values{"access_token": {pageAccessToken}},
+					wantRequestBody:    `{"subscribed_fields":["messages","messaging_postbacks"]}`,
+					returnResponse:     `{"success":true}`,
+					returnResponseCode: 200,
+				},
+				want: &SubscriptionResponse{Success: true},
+			}
+		},
+		"unsubscribe app success": func(t *testing.T) test {
+			return test{
+				call: func(ctx context.Context, client *Client) (interface{}, error) {
+					return client.UnsubscribeApp(ctx, pageID)
+				},
+				fields: fields{
+					wantMethod:         http.MethodDelete,
+					wantEndpoint:       GetAPIEndpointSubscribedApps(pageID),
+					wantQuery:          url.Values{"access_token": {pageAccessToken}},
+					returnResponse:     `{"success":true}`,
+					returnResponseCode: 200,
+				},
+				want: &SubscriptionResponse{Success: true},
+			}
+		},
+		"get subscribed apps success": func(t *testing.T) test {
+			return test{
+				call: func(ctx context.Context, client *Client) (interface{}, error) {
+					return client.GetSubscribedApps(ctx, pageID)
+				},
+				fields: fields{
+					wantMethod:   http.MethodGet,
+					wantEndpoint: GetAPIEndpointSubscribedApps(pageID),
+					wantQuery:    url.Values{"access_token": {pageAccessToken}},
+					returnResponse: `{
+						"data": [
+							{
+								"id": "app_id",
+								"name": "app_name",
+								"link": "https://example.com",
+								"subscribed_fields": ["messages", "standby"]
+							}
+						]
+					}`,
+					returnResponseCode: 200,
+				},
+				want: &GetSubscribedAppsResponse{
+					Data: []SubscribedApp{
+						{
+							ID:               "app_id",
+							Name:             "app_name",
+							Link:             "https://example.com",
+							SubscribedFields: []WebhookField{WebhookFieldMessages, WebhookFieldStandby},
+						},
+					},
+				},
+			}
+		},
+		"set app subscription success": func(t *testing.T) test {
+			return test{
+				call: func(ctx context.Context, client *Client) (interface{}, error) {
+					return client.SetAppSubscription(ctx, "https://example.com/webhook", "verify_token", []WebhookField{
+						WebhookFieldMessages,
+						WebhookFieldComments,
+					})
+				},
+				fields: fields{
+					wantMethod:   http.MethodPost,
+					wantEndpoint: GetAPIEndpointAppSubscriptions("app_id"),
+					wantQuery:    url.Values{"access_token": {appAccessToken}},
+					wantRequestBody: `{
+						"object": "instagram",
+						"callback_url": "https://example.com/webhook",
+						"verify_token": "verify_token",
+						"fields": ["messages", "comments"],
+						"include_values": true
+					}`,
+					returnResponse:     `{"success":true}`,
+					returnResponseCode: 200,
+				},
+				want: &SubscriptionResponse{Success: true},
+			}
+		},
+		"get app subscriptions success": func(t *testing.T) test {
+			return test{
+				call: func(ctx context.Context, client *Client) (interface{}, error) {
+					return client.GetAppSubscriptions(ctx)
+				},
+				fields: fields{
+					wantMethod:   http.MethodGet,
+					wantEndpoint: GetAPIEndpointAppSubscriptions("app_id"),
+					wantQuery:    url.Values{"access_token": {appAccessToken}},
+					returnResponse: `{
+						"data": [
+							{
+								"object": "instagram",
+								"callback_url": "https://example.com/webhook",
+								"active": true,
+								"fields": [
+									{
+										"name": "messages",
+										"version": "v11.0"
+									}
+								]
+							}
+						]
+					}`,
+					returnResponseCode: 200,
+				},
+				want: &GetAppSubscriptionsResponse{
+					Data: []AppSubscription{
+						{
+							Object:      "instagram",
+							CallbackURL: "https://example.com/webhook",
+							Active:      true,
+							Fields: []AppSubscriptionField{
+								{
+									Name:    WebhookFieldMessages,
+									Version: "v11.0",
+								},
+							},
+						},
+					},
+				},
+			}
+		},
+		"delete app subscription error": func(t *testing.T) test {
+			return test{
+				call: func(ctx context.Context, client *Client) (interface{}, error) {
+					return client.DeleteAppSubscription(ctx)
+				},
+				fields: fields{
+					wantMethod:   http.MethodDelete,
+					wantEndpoint: GetAPIEndpointAppSubscriptions("app_id"),
+					wantQuery: url.Values{
+						"access_token": {appAccessToken},
+						"object":       {"instagram"},
+					},
+					returnResponse: `{
+						"error": {
+							"message": "error",
+							"type": "OAuthException",
+							"code": 100,
+							"fbtrace_id": "fbtrace_id"
+						}
+					}`,
+					returnResponseCode: 400,
+				},
+				wantErr: &ErrorResponse{
+					StatusCode: 400,
+					APIError: APIError{
+						Message:   "error",
+						Type:      "OAuthException",
+						Code:      100,
+						FbTraceID: "fbtrace_id",
+					},
+				},
+			}
+		},
+	}
+
+	var currentTest string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tc := tests[currentTest](t)
+
+		assert.Equal(t, tc.fields.wantMethod, r.Method)
+
+		assert.Equal(t, tc.fields.wantEndpoint, r.URL.Path)
+
+		assert.Equal(t, tc.fields.wantQuery, r.URL.Query())
+
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if tc.fields.wantRequestBody != "" {
+			assert.JSONEq(t, tc.fields.wantRequestBody, string(body))
+		}
+
+		w.WriteHeader(tc.fields.returnResponseCode)
+		w.Write([]byte(tc.fields.returnResponse))
+	}))
+	defer mockServer.Close()
+
+	for name, fn := range tests {
+		currentTest = name
+		tt := fn(t)
+
+		t.Run(name, func(t *testing.T) {
+			client, err := New(
+				pageAccessToken,
+				WithEndpointBase(mockServer.URL),
+				WithAppCredentials("app_id", "app_secret"),
+			)
+			assert.NoError(t, err)
+
+			res, err := tt.call(context.Background(), client)
+			if tt.wantErr != nil {
+				assert.EqualError(t, tt.wantErr, err.Error())
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, res)
+		})
+	}
+}
+
+func TestAppSubscriptionWithoutAppCredentials(t *testing.T) {
+	client, err := New("page_access_token")
+	assert.NoError(t, err)
+
+	_, err = client.GetAppSubscriptions(context.Background())
+	assert.Equal(t, ErrMissingAppCredentials, err)
+}
@@ -1,7 +1,10 @@
 package instabot
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"strings"
 )
 
 // WebhookEventType defines webhook event type.
@@ -17,14 +20,32 @@ const (
 	WebhookEventTypeShare        WebhookEventType = WebhookEventType("share")
 	WebhookEventTypeMessageReply WebhookEventType = WebhookEventType("message_reply")
 	WebhookEventTypeStoryMention WebhookEventType = WebhookEventType("story_mention")
+	WebhookEventTypeIGReel       WebhookEventType = WebhookEventType("ig_reel")
+	WebhookEventTypeReel         WebhookEventType = WebhookEventType("reel")
+	WebhookEventTypeIGPost       WebhookEventType = WebhookEventType("ig_post")
+	WebhookEventTypeStory        WebhookEventType = WebhookEventType("story")
+	WebhookEventTypeTemplate     WebhookEventType = WebhookEventType("template")
+	WebhookEventTypeFallback     WebhookEventType = WebhookEventType("fallback")
 	WebhookEventTypeStoryReply   WebhookEventType = WebhookEventType("story_reply")
 	WebhookEventTypeQuickReply   WebhookEventType = WebhookEventType("quick_reply")
 	WebhookEventTypeReaction     WebhookEventType = WebhookEventType("reaction")
 	WebhookEventTypeMessageSeen  WebhookEventType = WebhookEventType("message_seen")
 	WebhookEventTypePostBack     WebhookEventType = WebhookEventType("postback")
+	WebhookEventTypeReferral     WebhookEventType = WebhookEventType("referral")
 	WebhookEventTypeEcho         WebhookEventType = WebhookEventType("echo")
 	WebhookEventTypeDeleted      WebhookEventType = WebhookEventType("deleted")
 	WebhookEventTypeUnsupported  WebhookEventType = WebhookEventType("unsupported")
+
+	// feed change event types, delivered under entry changes.
+	WebhookEventTypeComment       WebhookEventType = WebhookEventType("comments")
+	WebhookEventTypeLiveComment   WebhookEventType = WebhookEventType("live_comments")
+	WebhookEventTypeMention       WebhookEventType = WebhookEventType("mentions")
+	WebhookEventTypeStoryInsights WebhookEventType = WebhookEventType("story_insights")
+
+	// handover protocol event types.
+	WebhookEventTypePassThreadControl    WebhookEventType = WebhookEventType("pass_thread_control")
+	WebhookEventTypeTakeThreadControl    WebhookEventType = WebhookEventType("take_thread_control")
+	WebhookEventTypeRequestThreadControl WebhookEventType = WebhookEventType("request_thread_control")
 )
 
 // ReplyToStory defines story details.
@@ -39,9 +60,37 @@ type ReplyTo struct {
 	Story *ReplyToStory `json:"story"`
 }
 
+// WebhookTemplateButton defines button of a template
+// received in webhook, like in echo of a template message.
+type WebhookTemplateButton struct {
+	Type    string `json:"type"`
+	Title   string `json:"title"`
+	URL     string `json:"url"`
+	Payload string `json:"payload"`
+}
+
+// WebhookTemplateElement defines element of a template received in webhook.
+type WebhookTemplateElement struct {
+	ID            string                   `json:"id"`
+	Title         string                   `json:"title"`
+	Subtitle      string                   `json:"subtitle"`
+	ImageURL      string                   `json:"image_url"`
+	DefaultAction *WebhookTemplateButton   `json:"default_action"`
+	Buttons       []*WebhookTemplateButton `json:"buttons"`
+}
+
 // AttachmentPayload defines different attachment payload.
+// Title is set for ig_reel, reel, ig_post and fallback attachments,
+// ReelVideoID for reels, IGPostMediaID for ig posts, ID for stories,
+// TemplateType and Elements for template attachments.
 type AttachmentPayload struct {
-	URL string `json:"url"`
+	URL           string                    `json:"url"`
+	Title         string                    `json:"title"`
+	ReelVideoID   string                    `json:"reel_video_id"`
+	IGPostMediaID string                    `json:"ig_post_media_id"`
+	ID            string                    `json:"id"`
+	TemplateType  string                    `json:"template_type"`
+	Elements      []*WebhookTemplateElement `json:"elements"`
 }
 
 // Attachment defines attachment for different type like audio, video, media share, etc.
@@ -50,15 +99,52 @@ type Attachment struct {
 	Payload AttachmentPayload `json:"payload"`
 }
 
+// EventType returns webhook event type of the attachment,
+// empty when the attachment type is unknown.
+func (a *Attachment) EventType() WebhookEventType {
+	switch t := WebhookEventType(a.Type); t {
+	case WebhookEventTypeImageMessage,
+		WebhookEventTypeAudioMessage,
+		WebhookEventTypeVideoMessage,
+		WebhookEventTypeFileMessage,
+		WebhookEventTypeShare,
+		WebhookEventTypeStoryMention,
+		WebhookEventTypeIGReel,
+		WebhookEventTypeReel,
+		WebhookEventTypeIGPost,
+		WebhookEventTypeStory,
+		WebhookEventTypeTemplate,
+		WebhookEventTypeFallback:
+		return t
+	}
+
+	return ""
+}
+
 // ReferralProduct defines fb/instagram shop product.
 type ReferralProduct struct {
 	ID string `json:"id"`
 }
 
-// Referral holds product referral.
-// TODO: integrate later
+// ReferralAdsContextData defines ad details of a referral from an ad.
+type ReferralAdsContextData struct {
+	AdTitle   string `json:"ad_title"`
+	PhotoURL  string `json:"photo_url"`
+	VideoURL  string `json:"video_url"`
+	PostID    string `json:"post_id"`
+	ProductID string `json:"product_id"`
+}
+
+// Referral holds referral details of a conversation started
+// from an ig.me link, an ad or a product.
+// https://developers.facebook.com/docs/messenger-platform/instagram/features/webhook#referral
 type Referral struct {
-	Product ReferralProduct `json:"product"`
+	Ref            string                  `json:"ref"`
+	Source         string                  `json:"source"`
+	Type           string                  `json:"type"`
+	AdID           string                  `json:"ad_id"`
+	AdsContextData *ReferralAdsContextData `json:"ads_context_data"`
+	Product        ReferralProduct         `json:"product"`
 }
 
 // Reaction defines reaction.
@@ -79,23 +165,38 @@ type WebhookQuickReply struct {
 	Payload string `json:"payload"`
 }
 
+// WebhookEchoQuickReply defines quick reply of a message
+// sent by the instagram account, delivered in echo.
+type WebhookEchoQuickReply struct {
+	ContentType string `json:"content_type"`
+	Title       string `json:"title"`
+	Payload     string `json:"payload"`
+	ImageURL    string `json:"image_url"`
+}
+
 // Postback defines postback.
 type Postback struct {
-	MID     string `json:"mid"`
-	Title   string `json:"title"`
-	Payload string `json:"payload"`
+	MID      string    `json:"mid"`
+	Title    string    `json:"title"`
+	Payload  string    `json:"payload"`
+	Referral *Referral `json:"referral"`
 }
 
 // WebhookMessage defines different message event type details.
+// AppID, Metadata and QuickReplies are only set on echo.
 type WebhookMessage struct {
-	MID           string             `json:"mid"`
-	Text          string             `json:"text"`
-	QuickReply    *WebhookQuickReply `json:"quick_reply"`
-	Attachments   []*Attachment      `json:"attachments"`
-	ReplyTo       *ReplyTo           `json:"reply_to"`
-	IsEcho        bool               `json:"is_echo"`
-	IsUnsupported bool               `json:"is_unsupported"`
-	IsDeleted     bool               `json:"is_deleted"`
+	MID           string                   `json:"mid"`
+	Text          string                   `json:"text"`
+	QuickReply    *WebhookQuickReply       `json:"quick_reply"`
+	QuickReplies  []*WebhookEchoQuickReply `json:"quick_replies"`
+	Attachments   []*Attachment            `json:"attachments"`
+	ReplyTo       *ReplyTo                 `json:"reply_to"`
+	Referral      *Referral                `json:"referral"`
+	AppID         AppID                    `json:"app_id"`
+	Metadata      string                   `json:"metadata"`
+	IsEcho        bool                     `json:"is_echo"`
+	IsUnsupported bool                     `json:"is_unsupported"`
+	IsDeleted     bool                     `json:"is_deleted"`
 }
 
 func (m *WebhookMessage) isQuickReply() bool {
@@ -110,71 +211,58 @@ func (m *WebhookMessage) isMessageReply() bool {
 	return m.ReplyTo != nil && m.ReplyTo.MID != ""
 }
 
-func (m *WebhookMessage) isStoryMention() bool {
-	if len(m.Attachments) > 0 &&
-		m.Attachments[0].Type == string(WebhookEventTypeStoryMention) {
-		return true
-	}
-
-	return false
-}
-
-func (m *WebhookMessage) isShare() bool {
-	if len(m.Attachments) > 0 &&
-		m.Attachments[0].Type == string(WebhookEventTypeShare) {
-		return true
+// replyTo returns message id and story the message replies to.
+func (m *WebhookMessage) replyTo() (string, *ReplyToStory) {
+	if m.ReplyTo == nil {
+		return "", nil
 	}
 
-	return false
+	return m.ReplyTo.MID, m.ReplyTo.Story
 }
 
-func (m *WebhookMessage) isImageMessage() bool {
-	if len(m.Attachments) > 0 &&
-		m.Attachments[0].Type == string(WebhookEventTypeImageMessage) {
-		return true
+// attachmentEventType returns event type of the message derived from
+// its first attachment, empty when it has no attachment of a known type.
+func (m *WebhookMessage) attachmentEventType() WebhookEventType {
+	if len(m.Attachments) == 0 || m.Attachments[0] == nil {
+		return ""
 	}
 
-	return false
+	return m.Attachments[0].EventType()
 }
 
-func (m *WebhookMessage) isAudioMessage() bool {
-	if len(m.Attachments) > 0 &&
-		m.Attachments[0].Type == string(WebhookEventTypeAudioMessage) {
-		return true
-	}
-
-	return false
-}
-
-func (m *WebhookMessage) isVideoMessage() bool {
-	if len(m.Attachments) > 0 &&
-		m.Attachments[0].Type == string(WebhookEventTypeVideoMessage) {
-		return true
-	}
-
-	return false
+// Messaging defines events.
+// Standby is set for events delivered under entry standby,
+// received while another app owns the conversation.
+// Raw holds the event JSON as delivered, including fields
+// unknown to instabot. It is not updated when fields are changed,
+// set it to nil after changing them so that they are encoded.
+type Messaging struct {
+	Type                 WebhookEventType      `json:"-"`
+	Standby              bool                  `json:"-"`
+	Raw                  json.RawMessage       `json:"-"`
+	Sender               *Sender               `json:"sender"`
+	Recipient            *Recipient            `json:"recipient"`
+	Timestamp            int64                 `json:"timestamp"`
+	Message              *WebhookMessage       `json:"message,omitempty"`
+	Read                 *Read                 `json:"read,omitempty"`
+	Reaction             *Reaction             `json:"reaction,omitempty"`
+	Referral             *Referral             `json:"referral,omitempty"`
+	PostBack             *Postback             `json:"postback,omitempty"`
+	PassThreadControl    *PassThreadControl    `json:"pass_thread_control,omitempty"`
+	TakeThreadControl    *TakeThreadControl    `json:"take_thread_control,omitempty"`
+	RequestThreadControl *RequestThreadControl `json:"request_thread_control,omitempty"`
 }
 
-func (m *WebhookMessage) isFileMessage() bool {
-	if len(m.Attachments) > 0 &&
-		m.Attachments[0].Type == string(WebhookEventTypeFileMessage) {
-		return true
+// MarshalJSON returns Raw when the event is decoded from JSON,
+// so that it is encoded exactly as delivered.
+func (m *Messaging) MarshalJSON() ([]byte, error) {
+	if len(m.Raw) > 0 {
+		return m.Raw, nil
 	}
 
-	return false
-}
+	type messaging Messaging
 
-// Messaging defines events.
-type Messaging struct {
-	Type      WebhookEventType
-	Sender    *Sender         `json:"sender"`
-	Recipient *Recipient      `json:"recipient"`
-	Timestamp int64           `json:"timestamp"`
-	Message   *WebhookMessage `json:"message"`
-	Read      *Read           `json:"read"`
-	Reaction  *Reaction       `json:"reaction"`
-	Referral  *Referral       `json:"referral"`
-	PostBack  *Postback       `json:"postback"`
+	return json.Marshal((*messaging)(m))
 }
 
 func (m *Messaging) isMessageEvent() bool {
@@ -197,96 +285,229 @@ func (m *Messaging) isPostBackEvent() bool {
 	return m.PostBack != nil
 }
 
+// HasReferral reports whether the event carries a referral,
+// either standalone or nested inside a message or postback.
+func (m *Messaging) HasReferral() bool {
+	return m.referral() != nil
+}
+
+func (m *Messaging) referral() *Referral {
+	switch {
+	case m.Referral != nil:
+		return m.Referral
+	case m.Message != nil && m.Message.Referral != nil:
+		return m.Message.Referral
+	case m.PostBack != nil && m.PostBack.Referral != nil:
+		return m.PostBack.Referral
+	}
+
+	return nil
+}
+
 // Entry defines entry.
+// Messaging webhooks are delivered under Messaging, or under Standby
+// while another app owns the conversation, feed webhooks
+// (comments, mentions, story insights) under Changes.
+// Raw holds the entry JSON as delivered. It is not updated when fields
+// are changed, set it to nil after changing them so that they are encoded.
 type Entry struct {
-	ID        string       `json:"id"`
-	Time      int64        `json:"time"`
-	Messaging []*Messaging `json:"messaging"`
+	ID        string          `json:"id"`
+	Time      int64           `json:"time"`
+	Raw       json.RawMessage `json:"-"`
+	Messaging []*Messaging    `json:"messaging,omitempty"`
+	Standby   []*Messaging    `json:"standby,omitempty"`
+	Changes   []*Change       `json:"changes,omitempty"`
+}
+
+// MarshalJSON returns Raw when the entry is decoded from JSON,
+// so that it is encoded exactly as delivered.
+func (e *Entry) MarshalJSON() ([]byte, error) {
+	if len(e.Raw) > 0 {
+		return e.Raw, nil
+	}
+
+	type entry Entry
+
+	return json.Marshal((*entry)(e))
 }
 
 // WebhookEvent defines instagram webhook event payload.
+// Raw holds the payload JSON as delivered. It is not updated when fields
+// are changed, set it to nil after changing them so that they are encoded.
 type WebhookEvent struct {
-	Object  string   `json:"object"`
-	Entries []*Entry `json:"entry"`
+	Object  string          `json:"object"`
+	Raw     json.RawMessage `json:"-"`
+	Entries []*Entry        `json:"entry"`
+}
+
+// MarshalJSON returns Raw when the payload is decoded from JSON,
+// so that it is encoded exactly as delivered, unknown fields included.
+func (e *WebhookEvent) MarshalJSON() ([]byte, error) {
+	if len(e.Raw) > 0 {
+		return e.Raw, nil
+	}
+
+	type webhookEvent WebhookEvent
+
+	return json.Marshal((*webhookEvent)(e))
 }
 
 func (e *WebhookEvent) setType() {
 	for _, entry := range e.Entries {
 		for _, event := range entry.Messaging {
-			switch {
-			case event.isMessageEvent():
-				switch {
-				case event.Message.IsEcho:
-					event.Type = WebhookEventTypeEcho
-				case event.Message.IsDeleted:
-					event.Type = WebhookEventTypeDeleted
-				case event.Message.IsUnsupported:
-					event.Type = WebhookEventTypeUnsupported
-				case event.Message.isAudioMessage():
-					event.Type = WebhookEventTypeAudioMessage
-				case event.Message.isFileMessage():
-					event.Type = WebhookEventTypeFileMessage
-				case event.Message.isImageMessage():
-					event.Type = WebhookEventTypeImageMessage
-				case event.Message.isVideoMessage():
-					event.Type = WebhookEventTypeVideoMessage
-				case event.Message.isMessageReply():
-					event.Type = WebhookEventTypeMessageReply
-				case event.Message.isQuickReply():
-					event.Type = WebhookEventTypeQuickReply
-				case event.Message.isShare():
-					event.Type = WebhookEventTypeShare
-				case event.Message.isStoryMention():
-					event.Type = WebhookEventTypeStoryMention
-				case event.Message.isStoryReply():
-					event.Type = WebhookEventTypeStoryReply
-				case event.Message.Text != "":
-					event.Type = WebhookEventTypeTextMessage
-				}
-			case event.isMessageSeenEvent():
-				event.Type = WebhookEventTypeMessageSeen
-			case event.isReactionEvent():
-				event.Type = WebhookEventTypeReaction
-			case event.isPostBackEvent():
-				event.Type = WebhookEventTypePostBack
-			}
+			event.setType()
+		}
+
+		for _, event := range entry.Standby {
+			event.Standby = true
+			event.setType()
+		}
+
+		for _, change := range entry.Changes {
+			change.setType()
 		}
 	}
 }
 
+// setType classifies the event. Messages with attachments are classified
+// by the type of their first attachment, even when they also carry text
+// or reply to a message, the flattened events keep the text, reply and
+// every attachment so no content of mixed messages is dropped.
+// Attachments of unknown type do not classify the message,
+// they are kept on the text, quick reply and reply events.
+func (m *Messaging) setType() {
+	switch {
+	case m.isMessageEvent():
+		switch {
+		case m.Message.IsEcho:
+			m.Type = WebhookEventTypeEcho
+		case m.Message.IsDeleted:
+			m.Type = WebhookEventTypeDeleted
+		case m.Message.IsUnsupported:
+			m.Type = WebhookEventTypeUnsupported
+		case m.Message.attachmentEventType() != "":
+			m.Type = m.Message.attachmentEventType()
+		case m.Message.isMessageReply():
+			m.Type = WebhookEventTypeMessageReply
+		case m.Message.isQuickReply():
+			m.Type = WebhookEventTypeQuickReply
+		case m.Message.isStoryReply():
+			m.Type = WebhookEventTypeStoryReply
+		case m.Message.Text != "":
+			m.Type = WebhookEventTypeTextMessage
+		}
+	case m.isMessageSeenEvent():
+		m.Type = WebhookEventTypeMessageSeen
+	case m.isReactionEvent():
+		m.Type = WebhookEventTypeReaction
+	case m.isPostBackEvent():
+		m.Type = WebhookEventTypePostBack
+	case m.isReferralEvent():
+		m.Type = WebhookEventTypeReferral
+	case m.PassThreadControl != nil:
+		m.Type = WebhookEventTypePassThreadControl
+	case m.TakeThreadControl != nil:
+		m.Type = WebhookEventTypeTakeThreadControl
+	case m.RequestThreadControl != nil:
+		m.Type = WebhookEventTypeRequestThreadControl
+	}
+}
+
+type decodeOptions struct {
+	strict bool
+}
+
+// DecodeOption defines optional argument of webhook event decoding.
+type DecodeOption func(*decodeOptions)
+
+// StrictDecoding makes decoding fail on fields unknown to instabot
+// with ErrUnknownWebhookField, and on events none of the webhook event
+// types matches or holding attachments of unknown type with ErrUnclassifiedWebhookEvent.
+func StrictDecoding() DecodeOption {
+	return func(o *decodeOptions) {
+		o.strict = true
+	}
+}
+
+// DecodeWebhookEvent decodes webhook event payload.
+func DecodeWebhookEvent(payload []byte, options ...DecodeOption) (*WebhookEvent, error) {
+	opts := decodeOptions{}
+	for _, option := range options {
+		option(&opts)
+	}
+
+	e := new(WebhookEvent)
+	if err := e.decode(payload, opts); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
 // UnmarshalJSON unmarshal json webhook events.
 func (e *WebhookEvent) UnmarshalJSON(buffer []byte) error {
+	return e.decode(buffer, decodeOptions{})
+}
+
+func (e *WebhookEvent) decode(buffer []byte, opts decodeOptions) error {
 	type rawEntry struct {
-		ID        string       `json:"id"`
-		Time      int64        `json:"time"`
-		Messaging []*Messaging `json:"messaging"`
-		Message   []*Messaging `json:"message"`
+		ID        string            `json:"id"`
+		Time      int64             `json:"time"`
+		Messaging []json.RawMessage `json:"messaging"`
+		Message   []json.RawMessage `json:"message"`
+		Standby   []json.RawMessage `json:"standby"`
+		Changes   []*Change         `json:"changes"`
 	}
 
-	// TODO: use this wrapper to parse referral & other
-	// inconsistent structure later
 	type rawWebhookEvent struct {
-		Object  string      `json:"object"`
-		Entries []*rawEntry `json:"entry"`
+		Object  string            `json:"object"`
+		Entries []json.RawMessage `json:"entry"`
 	}
 
 	re := rawWebhookEvent{}
 
-	if err := json.Unmarshal(buffer, &re); err != nil {
+	if err := unmarshalJSON(buffer, &re, opts.strict); err != nil {
 		return err
 	}
 
 	e.Object = re.Object
+	e.Raw = append(json.RawMessage(nil), buffer...)
+	e.Entries = nil
+
+	for _, raw := range re.Entries {
+		rEntry := rawEntry{}
+		if err := unmarshalJSON(raw, &rEntry, opts.strict); err != nil {
+			return err
+		}
 
-	for _, rEntry := range re.Entries {
 		eEntry := Entry{
-			ID:        rEntry.ID,
-			Time:      rEntry.Time,
-			Messaging: rEntry.Messaging,
+			ID:      rEntry.ID,
+			Time:    rEntry.Time,
+			Raw:     raw,
+			Changes: rEntry.Changes,
 		}
 
+		messaging := rEntry.Messaging
 		if len(rEntry.Message) > 0 && len(rEntry.Messaging) == 0 {
-			eEntry.Messaging = rEntry.Message
+			messaging = rEntry.Message
+		}
+
+		var err error
+
+		if eEntry.Messaging, err = decodeMessaging(messaging, opts.strict); err != nil {
+			return err
+		}
+
+		if eEntry.Standby, err = decodeMessaging(rEntry.Standby, opts.strict); err != nil {
+			return err
+		}
+
+		for _, change := range eEntry.Changes {
+			change.accountID = eEntry.ID
+
+			if err := change.decodeValue(opts.strict); err != nil {
+				return err
+			}
 		}
 
 		e.Entries = append(e.Entries, &eEntry)
@@ -294,5 +515,77 @@ func (e *WebhookEvent) UnmarshalJSON(buffer []byte) error {
 
 	e.setType()
 
+	if opts.strict {
+		return e.checkClassified()
+	}
+
+	return nil
+}
+
+func decodeMessaging(raws []json.RawMessage, strict bool) ([]*Messaging, error) {
+	if raws == nil {
+		return nil, nil
+	}
+
+	messaging := make([]*Messaging, 0, len(raws))
+
+	for _, raw := range raws {
+		m := &Messaging{}
+		if err := unmarshalJSON(raw, m, strict); err != nil {
+			return nil, err
+		}
+
+		m.Raw = raw
+
+		messaging = append(messaging, m)
+	}
+
+	return messaging, nil
+}
+
+// checkClassified reports events none of the webhook event types matches.
+func (e *WebhookEvent) checkClassified() error {
+	for _, entry := range e.Entries {
+		for _, m := range append(append([]*Messaging{}, entry.Messaging...), entry.Standby...) {
+			if m.Type == "" {
+				return fmt.Errorf("%w: %s", ErrUnclassifiedWebhookEvent, m.Raw)
+			}
+
+			if m.Message != nil {
+				for _, a := range m.Message.Attachments {
+					if a != nil && a.EventType() == "" {
+						return fmt.Errorf("%w: attachment type %s", ErrUnclassifiedWebhookEvent, a.Type)
+					}
+				}
+			}
+		}
+
+		for _, c := range entry.Changes {
+			if c.Type == "" {
+				return fmt.Errorf("%w: change field %s", ErrUnclassifiedWebhookEvent, c.Field)
+			}
+		}
+	}
+
+	return nil
+}
+
+// unmarshalJSON unmarshal buffer into v, failing on unknown fields when strict.
+func unmarshalJSON(buffer []byte, v interface{}, strict bool) error {
+	if !strict {
+		return json.Unmarshal(buffer, v)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(buffer))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(v); err != nil {
+		if strings.HasPrefix(err.Error(), "json: unknown field") {
+			return fmt.Errorf("%w: %v", ErrUnknownWebhookField, err)
+		}
+
+		return err
+	}
+
 	return nil
 }
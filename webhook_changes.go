@@ -0,0 +1,167 @@
+package instabot
+
+import "encoding/json"
+
+// CommentAuthor defines instagram user who wrote a comment.
+type CommentAuthor struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+}
+
+// CommentMedia defines media a comment is written on.
+type CommentMedia struct {
+	ID               string `json:"id"`
+	MediaProductType string `json:"media_product_type"`
+}
+
+// Comment defines comments and live_comments change value.
+// https://developers.facebook.com/docs/instagram-api/guides/webhooks#comments
+type Comment struct {
+	ID       string         `json:"id"`
+	Text     string         `json:"text"`
+	ParentID string         `json:"parent_id"`
+	From     *CommentAuthor `json:"from"`
+	Media    *CommentMedia  `json:"media"`
+}
+
+// Mention defines mentions change value.
+// https://developers.facebook.com/docs/instagram-api/guides/webhooks#mentions
+type Mention struct {
+	MediaID   string `json:"media_id"`
+	CommentID string `json:"comment_id"`
+}
+
+// StoryInsights defines story_insights change value.
+// https://developers.facebook.com/docs/instagram-api/guides/webhooks#story-insights
+type StoryInsights struct {
+	MediaID     string `json:"media_id"`
+	Impressions int64  `json:"impressions"`
+	Reach       int64  `json:"reach"`
+	TapsForward int64  `json:"taps_forward"`
+	TapsBack    int64  `json:"taps_back"`
+	Exits       int64  `json:"exits"`
+	Replies     int64  `json:"replies"`
+}
+
+// Change defines feed change events delivered under entry changes.
+// Value holds the raw change value, decoded into one of
+// Comment, Mention or StoryInsights depending on Field.
+type Change struct {
+	Type          WebhookEventType `json:"-"`
+	Field         string           `json:"field"`
+	Value         json.RawMessage  `json:"value"`
+	Comment       *Comment         `json:"-"`
+	Mention       *Mention         `json:"-"`
+	StoryInsights *StoryInsights   `json:"-"`
+
+	accountID string
+}
+
+// decodeValue decodes raw change value into its typed field,
+// failing on unknown value fields when strict.
+func (c *Change) decodeValue(strict bool) error {
+	if len(c.Value) == 0 {
+		return nil
+	}
+
+	switch WebhookEventType(c.Field) {
+	case WebhookEventTypeComment, WebhookEventTypeLiveComment:
+		c.Comment = new(Comment)
+
+		return unmarshalJSON(c.Value, c.Comment, strict)
+	case WebhookEventTypeMention:
+		c.Mention = new(Mention)
+
+		return unmarshalJSON(c.Value, c.Mention, strict)
+	case WebhookEventTypeStoryInsights:
+		c.StoryInsights = new(StoryInsights)
+
+		return unmarshalJSON(c.Value, c.StoryInsights, strict)
+	}
+
+	return nil
+}
+
+func (c *Change) setType() {
+	switch {
+	case c.Comment != nil:
+		c.Type = WebhookEventType(c.Field)
+	case c.Mention != nil:
+		c.Type = WebhookEventTypeMention
+	case c.StoryInsights != nil:
+		c.Type = WebhookEventTypeStoryInsights
+	}
+}
+
+// CommentEvent defines flatten comment event.
+type CommentEvent struct {
+	AccountID        string
+	Type             WebhookEventType
+	ID               string
+	Text             string
+	ParentID         string
+	From             *CommentAuthor
+	MediaID          string
+	MediaProductType string
+}
+
+// GetCommentEvent returns comment event.
+// Call this only when the event type is
+// WebhookEventTypeComment or WebhookEventTypeLiveComment.
+func (c *Change) GetCommentEvent() *CommentEvent {
+	commentEvent := &CommentEvent{
+		AccountID: c.accountID,
+		Type:      c.Type,
+	}
+
+	if c.Comment != nil {
+		commentEvent.ID = c.Comment.ID
+		commentEvent.Text = c.Comment.Text
+		commentEvent.ParentID = c.Comment.ParentID
+		commentEvent.From = c.Comment.From
+
+		if c.Comment.Media != nil {
+			commentEvent.MediaID = c.Comment.Media.ID
+			commentEvent.MediaProductType = c.Comment.Media.MediaProductType
+		}
+	}
+
+	return commentEvent
+}
+
+// MentionEvent defines flatten mention event.
+type MentionEvent struct {
+	AccountID string
+	MediaID   string
+	CommentID string
+}
+
+// GetMentionEvent returns mention event.
+// Call this only when the event type is WebhookEventTypeMention.
+func (c *Change) GetMentionEvent() *MentionEvent {
+	mentionEvent := &MentionEvent{
+		AccountID: c.accountID,
+	}
+
+	if c.Mention != nil {
+		mentionEvent.MediaID = c.Mention.MediaID
+		mentionEvent.CommentID = c.Mention.CommentID
+	}
+
+	return mentionEvent
+}
+
+// StoryInsightsEvent defines flatten story insights event.
+type StoryInsightsEvent struct {
+	AccountID string
+	Insights  *StoryInsights
+}
+
+// GetStoryInsightsEvent returns story insights event.
+// Call this only when the event type is WebhookEventTypeStoryInsights.
+func (c *Change) GetStoryInsightsEvent() *StoryInsightsEvent {
+	return &StoryInsightsEvent{
+		AccountID: c.accountID,
+		Insights:  c.StoryInsights,
+	}
+}
@@ -0,0 +1,251 @@
+package instabot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const changesTestPayload = `{
+	"object": "instagram",
+	"entry": [
+	  {
+		"id": "<IGID>",
+		"time": 1520622968,
+		"changes": [
+		  {
+			"field": "comments",
+			"value": {
+			  "from": {
+				"id": "<IGSID>",
+				"username": "<USERNAME>"
+			  },
+			  "media": {
+				"id": "<MEDIA_ID>",
+				"media_product_type": "FEED"
+			  },
+			  "id": "<COMMENT_ID>",
+			  "parent_id": "<PARENT_COMMENT_ID>",
+			  "text": "<COMMENT_TEXT>"
+			}
+		  },
+		  {
+			"field": "live_comments",
+			"value": {
+			  "from": {
+				"id": "<IGSID>",
+				"username": "<USERNAME>"
+			  },
+			  "media": {
+				"id": "<MEDIA_ID>",
+				"media_product_type": "LIVE"
+			  },
+			  "id": "<COMMENT_ID>",
+			  "text": "<COMMENT_TEXT>"
+			}
+		  },
+		  {
+			"field": "mentions",
+			"value": {
+			  "media_id": "<MEDIA_ID>",
+			  "comment_id": "<COMMENT_ID>"
+			}
+		  },
+		  {
+			"field": "story_insights",
+			"value": {
+			  "media_id": "<MEDIA_ID>",
+			  "impressions": 444,
+			  "reach": 44,
+			  "taps_forward": 4,
+			  "taps_back": 3,
+			  "exits": 3,
+			  "replies": 0
+			}
+		  },
+		  {
+			"field": "unknown_field",
+			"value": {
+			  "id": "<ID>"
+			}
+		  }
+		]
+	  }
+	]
+}`
+
+func TestWebhookChangeType(t *testing.T) {
+	e := new(WebhookEvent)
+	err := json.Unmarshal([]byte(changesTestPayload), e)
+	assert.NoError(t, err)
+
+	changes := e.Entries[0].Changes
+	assert.Len(t, changes, 5)
+	assert.Empty(t, e.Entries[0].Messaging)
+
+	assert.Equal(t, WebhookEventTypeComment, changes[0].Type)
+	assert.Equal(t, WebhookEventTypeLiveComment, changes[1].Type)
+	assert.Equal(t, WebhookEventTypeMention, changes[2].Type)
+	assert.Equal(t, WebhookEventTypeStoryInsights, changes[3].Type)
+	assert.Equal(t, WebhookEventType(""), changes[4].Type)
+	assert.JSONEq(t, `{"id": "<ID>"}`, string(changes[4].Value))
+}
+
+func TestGetChangeEvents(t *testing.T) {
+	e := new(WebhookEvent)
+	err := json.Unmarshal([]byte(changesTestPayload), e)
+	assert.NoError(t, err)
+
+	changes := e.Entries[0].Changes
+
+	assert.Equal(t, &CommentEvent{
+		AccountID:        "<IGID>",
+		Type:             WebhookEventTypeComment,
+		ID:               "<COMMENT_ID>",
+		Text:             "<COMMENT_TEXT>",
+		ParentID:         "<PARENT_COMMENT_ID>",
+		From:             &CommentAuthor{ID: "<IGSID>", Username: "<USERNAME>"},
+		MediaID:          "<MEDIA_ID>",
+		MediaProductType: "FEED",
+	}, changes[0].GetCommentEvent())
+
+	assert.Equal(t, &CommentEvent{
+		AccountID:        "<IGID>",
+		Type:             WebhookEventTypeLiveComment,
+		ID:               "<COMMENT_ID>",
+		Text:             "<COMMENT_TEXT>",
+		From:             &CommentAuthor{ID: "<IGSID>", Username: "<USERNAME>"},
+		MediaID:          "<MEDIA_ID>",
+		MediaProductType: "LIVE",
+	}, changes[1].GetCommentEvent())
+
+	assert.Equal(t, &MentionEvent{
+		AccountID: "<IGID>",
+		MediaID:   "<MEDIA_ID>",
+		CommentID: "<COMMENT_ID>",
+	}, changes[2].GetMentionEvent())
+
+	assert.Equal(t, &StoryInsightsEvent{
+		AccountID: "<IGID>",
+		Insights: &StoryInsights{
+			MediaID:     "<MEDIA_ID>",
+			Impressions: 444,
+			Reach:       44,
+			TapsForward: 4,
+			TapsBack:    3,
+			Exits:       3,
+		},
+	}, changes[3].GetStoryInsightsEvent())
+}
+
+func TestDispatcherChanges(t *testing.T) {
+	e := new(WebhookEvent)
+	err := json.Unmarshal([]byte(changesTestPayload), e)
+	assert.NoError(t, err)
+
+	called := map[WebhookEventType]int{}
+
+	d := NewDispatcher()
+	d.OnComment(func(ctx context.Context, event *CommentEvent) error {
+		called[event.Type]++
+
+		return nil
+	})
+	d.OnLiveComment(func(ctx context.Context, event *CommentEvent) error {
+		called[event.Type]++
+
+		return nil
+	})
+	d.OnMention(func(ctx context.Context, event *MentionEvent) error {
+		called[WebhookEventTypeMention]++
+
+		return nil
+	})
+	d.OnStoryInsights(func(ctx context.Context, event *StoryInsightsEvent) error {
+		called[WebhookEventTypeStoryInsights]++
+
+		return nil
+	})
+
+	assert.NoError(t, d.HandleWebhookEvent(context.Background(), e))
+	assert.Equal(t, map[WebhookEventType]int{
+		WebhookEventTypeComment:       1,
+		WebhookEventTypeLiveComment:   1,
+		WebhookEventTypeMention:       1,
+		WebhookEventTypeStoryInsights: 1,
+	}, called)
+}
+
+func TestDispatcherChangeMiddlewares(t *testing.T) {
+	var buf bytes.Buffer
+	called := map[WebhookEventType]int{}
+
+	d := NewDispatcher()
+	d.UseChange(
+		LoggerChange(log.New(&buf, "", 0)),
+		DedupChange(NewMemoryDedupStore(time.Hour, 100)),
+		RecoverChange(),
+	)
+	d.OnComment(func(ctx context.Context, event *CommentEvent) error {
+		called[event.Type]++
+
+		return nil
+	})
+	d.OnMention(func(ctx context.Context, event *MentionEvent) error {
+		panic("mention handler panic")
+	})
+
+	for i := 0; i < 2; i++ {
+		e := new(WebhookEvent)
+		assert.NoError(t, json.Unmarshal([]byte(changesTestPayload), e))
+
+		err := d.HandleWebhookEvent(context.Background(), e)
+		assert.True(t, errors.Is(err, ErrHandlerPanic))
+	}
+
+	// redelivered comment is handled once, failed mention on every delivery.
+	assert.Equal(t, 1, called[WebhookEventTypeComment])
+	assert.Equal(t, 2, strings.Count(buf.String(), "type=mentions account=<IGID>"))
+}
+
+func TestChangeDedupKey(t *testing.T) {
+	e := new(WebhookEvent)
+	assert.NoError(t, json.Unmarshal([]byte(changesTestPayload), e))
+
+	changes := e.Entries[0].Changes
+
+	assert.Equal(t, "comment:comments:<COMMENT_ID>", changes[0].DedupKey())
+	assert.Equal(t, "comment:live_comments:<COMMENT_ID>", changes[1].DedupKey())
+	assert.NotEqual(t, changes[2].DedupKey(), changes[3].DedupKey())
+
+	redelivered := new(WebhookEvent)
+	assert.NoError(t, json.Unmarshal([]byte(changesTestPayload), redelivered))
+	assert.Equal(t, changes[3].DedupKey(), redelivered.Entries[0].Changes[3].DedupKey())
+}
+
+func TestWebhookChangeInvalidValue(t *testing.T) {
+	e := new(WebhookEvent)
+	err := json.Unmarshal([]byte(`{
+		"object": "instagram",
+		"entry": [
+		  {
+			"id": "<IGID>",
+			"time": 1520622968,
+			"changes": [
+			  {
+				"field": "mentions",
+				"value": "not an object"
+			  }
+			]
+		  }
+		]
+	}`), e)
+	assert.Error(t, err)
+}
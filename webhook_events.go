@@ -2,8 +2,169 @@ package instabot
 
 import "time"
 
+// Event defines common accessors of flatten messaging events.
+// Getters are used as the flatten events already expose
+// these values as fields.
+type Event interface {
+	// GetType returns webhook event type.
+	GetType() WebhookEventType
+	// GetSender returns sender of the event.
+	GetSender() *Sender
+	// GetRecipient returns recipient of the event.
+	GetRecipient() *Recipient
+	// GetTimestamp returns time of the event.
+	GetTimestamp() time.Time
+	// GetMID returns id of the message or postback of the event, if any.
+	GetMID() string
+	// GetRaw returns messaging the event is flatten from.
+	GetRaw() *Messaging
+}
+
+// eventBase implements Event on top of the messaging
+// a flatten event is created from.
+type eventBase struct {
+	raw *Messaging
+}
+
+// GetType returns webhook event type.
+func (e eventBase) GetType() WebhookEventType {
+	if e.raw == nil {
+		return ""
+	}
+
+	return e.raw.Type
+}
+
+// GetSender returns sender of the event.
+func (e eventBase) GetSender() *Sender {
+	if e.raw == nil {
+		return nil
+	}
+
+	return e.raw.Sender
+}
+
+// GetRecipient returns recipient of the event.
+func (e eventBase) GetRecipient() *Recipient {
+	if e.raw == nil {
+		return nil
+	}
+
+	return e.raw.Recipient
+}
+
+// GetTimestamp returns time of the event.
+func (e eventBase) GetTimestamp() time.Time {
+	if e.raw == nil {
+		return time.Time{}
+	}
+
+	return e.raw.timestamp()
+}
+
+// GetMID returns id of the message or postback of the event, if any.
+func (e eventBase) GetMID() string {
+	if e.raw == nil {
+		return ""
+	}
+
+	return e.raw.mid()
+}
+
+// GetRaw returns messaging the event is flatten from.
+func (e eventBase) GetRaw() *Messaging {
+	return e.raw
+}
+
+// compile time interface implementation check.
+var (
+	_ Event = (*TextMessageEvent)(nil)
+	_ Event = (*MediaMessageEvent)(nil)
+	_ Event = (*MessageShareEvent)(nil)
+	_ Event = (*QuickReplyEvent)(nil)
+	_ Event = (*PostBackEvent)(nil)
+	_ Event = (*StoryMentionEvent)(nil)
+	_ Event = (*StoryReplyEvent)(nil)
+	_ Event = (*MessageReplyEvent)(nil)
+	_ Event = (*MessageReactionEvent)(nil)
+	_ Event = (*MessageSeenEvent)(nil)
+	_ Event = (*MessageDeleteEvent)(nil)
+	_ Event = (*EchoEvent)(nil)
+	_ Event = (*ReferralEvent)(nil)
+	_ Event = (*PassThreadControlEvent)(nil)
+	_ Event = (*TakeThreadControlEvent)(nil)
+	_ Event = (*RequestThreadControlEvent)(nil)
+)
+
+// timestamp converts the event millisecond unix timestamp to time.
+func (m *Messaging) timestamp() time.Time {
+	return time.Unix(0, m.Timestamp*int64(time.Millisecond)).UTC()
+}
+
+func (m *Messaging) mid() string {
+	switch {
+	case m.Message != nil:
+		return m.Message.MID
+	case m.PostBack != nil:
+		return m.PostBack.MID
+	}
+
+	return ""
+}
+
+// Event returns flatten event matching the event type,
+// nil when the type has no flatten event, like unsupported messages.
+func (m *Messaging) Event() Event {
+	switch m.Type {
+	case WebhookEventTypeTextMessage:
+		return m.GetTextMessageEvent()
+	case WebhookEventTypeImageMessage,
+		WebhookEventTypeAudioMessage,
+		WebhookEventTypeVideoMessage,
+		WebhookEventTypeFileMessage:
+		return m.GetMediaMessageEvent()
+	case WebhookEventTypeShare,
+		WebhookEventTypeIGReel,
+		WebhookEventTypeReel,
+		WebhookEventTypeIGPost,
+		WebhookEventTypeStory,
+		WebhookEventTypeFallback,
+		WebhookEventTypeTemplate:
+		return m.GetMessageShareEvent()
+	case WebhookEventTypeQuickReply:
+		return m.GetQuickReplyEvent()
+	case WebhookEventTypePostBack:
+		return m.GetPostBackEvent()
+	case WebhookEventTypeStoryMention:
+		return m.GetStoryMentionEvent()
+	case WebhookEventTypeStoryReply:
+		return m.GetStoryReplyEvent()
+	case WebhookEventTypeMessageReply:
+		return m.GetMessageReplyEvent()
+	case WebhookEventTypeReaction:
+		return m.GetMessageReactionEvent()
+	case WebhookEventTypeMessageSeen:
+		return m.GetMessageSeenEvent()
+	case WebhookEventTypeDeleted:
+		return m.GetMessageDeleteEvent()
+	case WebhookEventTypeEcho:
+		return m.GetEchoEvent()
+	case WebhookEventTypeReferral:
+		return m.GetReferralEvent()
+	case WebhookEventTypePassThreadControl:
+		return m.GetPassThreadControlEvent()
+	case WebhookEventTypeTakeThreadControl:
+		return m.GetTakeThreadControlEvent()
+	case WebhookEventTypeRequestThreadControl:
+		return m.GetRequestThreadControlEvent()
+	}
+
+	return nil
+}
+
 // PostBackEvent defines flatten data for postback event.
 type PostBackEvent struct {
+	eventBase
 	Sender    *Sender
 	Recipient *Recipient
 	Timestamp time.Time
@@ -16,19 +177,23 @@ func (m *Messaging) GetPostBackEvent() *PostBackEvent {
 	return &PostBackEvent{
 		Sender:    m.Sender,
 		Recipient: m.Recipient,
-		Timestamp: time.Unix(m.Timestamp, 0).UTC(),
+		Timestamp: m.timestamp(),
+		eventBase: eventBase{raw: m},
 		Data:      m.PostBack,
 	}
 }
 
 // QuickReplyEvent defines flatten quick reply event data.
+// Attachments holds attachments of types instabot does not classify, if any.
 type QuickReplyEvent struct {
-	Sender    *Sender
-	Recipient *Recipient
-	Timestamp time.Time
-	MID       string
-	Text      string
-	Data      *WebhookQuickReply
+	eventBase
+	Sender      *Sender
+	Recipient   *Recipient
+	Timestamp   time.Time
+	MID         string
+	Text        string
+	Data        *WebhookQuickReply
+	Attachments []*Attachment
 }
 
 // GetQuickReplyEvent returns quick reply event.
@@ -37,25 +202,34 @@ func (m *Messaging) GetQuickReplyEvent() *QuickReplyEvent {
 	quickReply := &QuickReplyEvent{
 		Sender:    m.Sender,
 		Recipient: m.Recipient,
-		Timestamp: time.Unix(m.Timestamp, 0).UTC(),
+		Timestamp: m.timestamp(),
+		eventBase: eventBase{raw: m},
 	}
 
 	if m.Message != nil {
 		quickReply.MID = m.Message.MID
 		quickReply.Text = m.Message.Text
 		quickReply.Data = m.Message.QuickReply
+		quickReply.Attachments = m.Message.Attachments
 	}
 
 	return quickReply
 }
 
 // StoryMentionEvent defines flatten story mention event.
+// Story holds the mentioning story, Attachments every attachment
+// of the message, ReplyToMID and ReplyToStory what the message replies to.
 type StoryMentionEvent struct {
-	Sender    *Sender
-	Recipient *Recipient
-	Timestamp time.Time
-	MID       string
-	Story     *ReplyToStory
+	eventBase
+	Sender       *Sender
+	Recipient    *Recipient
+	Timestamp    time.Time
+	MID          string
+	Story        *ReplyToStory
+	Attachments  []*Attachment
+	Text         string
+	ReplyToMID   string
+	ReplyToStory *ReplyToStory
 }
 
 // GetStoryMentionEvent returns story mention event.
@@ -64,12 +238,15 @@ func (m *Messaging) GetStoryMentionEvent() *StoryMentionEvent {
 	storyMentionEvent := &StoryMentionEvent{
 		Sender:    m.Sender,
 		Recipient: m.Recipient,
-		Timestamp: time.Unix(m.Timestamp, 0).UTC(),
-		MID:       m.Message.MID,
+		Timestamp: m.timestamp(),
+		eventBase: eventBase{raw: m},
 	}
 
 	if m.Message != nil {
 		storyMentionEvent.MID = m.Message.MID
+		storyMentionEvent.Text = m.Message.Text
+		storyMentionEvent.Attachments = m.Message.Attachments
+		storyMentionEvent.ReplyToMID, storyMentionEvent.ReplyToStory = m.Message.replyTo()
 
 		if len(m.Message.Attachments) > 0 {
 			storyMentionEvent.Story = &ReplyToStory{
@@ -82,13 +259,16 @@ func (m *Messaging) GetStoryMentionEvent() *StoryMentionEvent {
 }
 
 // StoryReplyEvent defines flatten story reply event.
+// Attachments holds attachments of types instabot does not classify, if any.
 type StoryReplyEvent struct {
-	Sender    *Sender
-	Recipient *Recipient
-	Timestamp time.Time
-	MID       string
-	Text      string
-	Story     *ReplyToStory
+	eventBase
+	Sender      *Sender
+	Recipient   *Recipient
+	Timestamp   time.Time
+	MID         string
+	Text        string
+	Story       *ReplyToStory
+	Attachments []*Attachment
 }
 
 // GetStoryReplyEvent returns story reply event.
@@ -97,12 +277,14 @@ func (m *Messaging) GetStoryReplyEvent() *StoryReplyEvent {
 	storyReplyEvent := &StoryReplyEvent{
 		Sender:    m.Sender,
 		Recipient: m.Recipient,
-		Timestamp: time.Unix(m.Timestamp, 0).UTC(),
+		Timestamp: m.timestamp(),
+		eventBase: eventBase{raw: m},
 	}
 
 	if m.Message != nil {
 		storyReplyEvent.MID = m.Message.MID
 		storyReplyEvent.Text = m.Message.Text
+		storyReplyEvent.Attachments = m.Message.Attachments
 
 		if m.Message.ReplyTo != nil {
 			storyReplyEvent.Story = m.Message.ReplyTo.Story
@@ -113,12 +295,15 @@ func (m *Messaging) GetStoryReplyEvent() *StoryReplyEvent {
 }
 
 // TextMessageEvent defines flatten text message event.
+// Attachments holds attachments of types instabot does not classify, if any.
 type TextMessageEvent struct {
-	Sender    *Sender
-	Recipient *Recipient
-	Timestamp time.Time
-	MID       string
-	Text      string
+	eventBase
+	Sender      *Sender
+	Recipient   *Recipient
+	Timestamp   time.Time
+	MID         string
+	Text        string
+	Attachments []*Attachment
 }
 
 // GetTextMessageEvent returns text message event.
@@ -127,43 +312,57 @@ func (m *Messaging) GetTextMessageEvent() *TextMessageEvent {
 	textMessageEvent := &TextMessageEvent{
 		Sender:    m.Sender,
 		Recipient: m.Recipient,
-		Timestamp: time.Unix(m.Timestamp, 0).UTC(),
-		MID:       m.Message.MID,
-		Text:      m.Message.Text,
+		Timestamp: m.timestamp(),
+		eventBase: eventBase{raw: m},
 	}
 
 	if m.Message != nil {
 		textMessageEvent.MID = m.Message.MID
 		textMessageEvent.Text = m.Message.Text
+		textMessageEvent.Attachments = m.Message.Attachments
 	}
 
 	return textMessageEvent
 }
 
 // MediaMessageEvent defines flatten media message event.
+// Media holds the first attachment payload, Attachments every
+// attachment of the message along with its own type,
+// ReplyToMID and ReplyToStory what the message replies to.
 type MediaMessageEvent struct {
-	Sender    *Sender
-	Recipient *Recipient
-	Timestamp time.Time
-	MID       string
-	Type      WebhookEventType
-	Media     *AttachmentPayload
+	eventBase
+	Sender       *Sender
+	Recipient    *Recipient
+	Timestamp    time.Time
+	MID          string
+	Type         WebhookEventType
+	Media        *AttachmentPayload
+	Attachments  []*Attachment
+	Text         string
+	ReplyToMID   string
+	ReplyToStory *ReplyToStory
 }
 
 // GetMediaMessageEvent returns media (image, audio, video, file) message event.
 // Call this only when the event type is one of
 // WebhookEventTypeImageMessage, WebhookEventTypeAudioeMessage,
-// WebhookEventTypeVideoMessage or WebhookEventTypeFileMessage.
+// WebhookEventTypeVideoMessage or WebhookEventTypeFileMessage,
+// the type being the one of the first attachment.
 func (m *Messaging) GetMediaMessageEvent() *MediaMessageEvent {
 	mediaMessageEvent := &MediaMessageEvent{
 		Type:      m.Type,
 		Sender:    m.Sender,
 		Recipient: m.Recipient,
-		Timestamp: time.Unix(m.Timestamp, 0).UTC(),
+		Timestamp: m.timestamp(),
+		eventBase: eventBase{raw: m},
 	}
 
 	if m.Message != nil {
 		mediaMessageEvent.MID = m.Message.MID
+		mediaMessageEvent.Text = m.Message.Text
+		mediaMessageEvent.Attachments = m.Message.Attachments
+
+		mediaMessageEvent.ReplyToMID, mediaMessageEvent.ReplyToStory = m.Message.replyTo()
 
 		if len(m.Message.Attachments) > 0 {
 			mediaMessageEvent.Media = &m.Message.Attachments[0].Payload
@@ -174,13 +373,16 @@ func (m *Messaging) GetMediaMessageEvent() *MediaMessageEvent {
 }
 
 // MessageReplyEvent defines flatten message reply event.
+// Attachments holds attachments of types instabot does not classify, if any.
 type MessageReplyEvent struct {
-	Sender     *Sender
-	Recipient  *Recipient
-	Timestamp  time.Time
-	MID        string
-	Text       string
-	ReplyToMID string
+	eventBase
+	Sender      *Sender
+	Recipient   *Recipient
+	Timestamp   time.Time
+	MID         string
+	Text        string
+	ReplyToMID  string
+	Attachments []*Attachment
 }
 
 // GetMessageReplyEvent returns message reply event.
@@ -189,12 +391,14 @@ func (m *Messaging) GetMessageReplyEvent() *MessageReplyEvent {
 	messageReplyEvent := &MessageReplyEvent{
 		Sender:    m.Sender,
 		Recipient: m.Recipient,
-		Timestamp: time.Unix(m.Timestamp, 0).UTC(),
+		Timestamp: m.timestamp(),
+		eventBase: eventBase{raw: m},
 	}
 
 	if m.Message != nil {
 		messageReplyEvent.MID = m.Message.MID
 		messageReplyEvent.Text = m.Message.Text
+		messageReplyEvent.Attachments = m.Message.Attachments
 
 		if m.Message.ReplyTo != nil {
 			messageReplyEvent.ReplyToMID = m.Message.ReplyTo.MID
@@ -206,6 +410,7 @@ func (m *Messaging) GetMessageReplyEvent() *MessageReplyEvent {
 
 // MessageReactionEvent defines flatten message react event.
 type MessageReactionEvent struct {
+	eventBase
 	Sender    *Sender
 	Recipient *Recipient
 	Timestamp time.Time
@@ -218,13 +423,15 @@ func (m *Messaging) GetMessageReactionEvent() *MessageReactionEvent {
 	return &MessageReactionEvent{
 		Sender:    m.Sender,
 		Recipient: m.Recipient,
-		Timestamp: time.Unix(m.Timestamp, 0).UTC(),
+		Timestamp: m.timestamp(),
+		eventBase: eventBase{raw: m},
 		Reaction:  m.Reaction,
 	}
 }
 
 // MessageSeenEvent defines message seen event.
 type MessageSeenEvent struct {
+	eventBase
 	Sender    *Sender
 	Recipient *Recipient
 	Timestamp time.Time
@@ -237,7 +444,8 @@ func (m *Messaging) GetMessageSeenEvent() *MessageSeenEvent {
 	messageSeenEvent := &MessageSeenEvent{
 		Sender:    m.Sender,
 		Recipient: m.Recipient,
-		Timestamp: time.Unix(m.Timestamp, 0).UTC(),
+		Timestamp: m.timestamp(),
+		eventBase: eventBase{raw: m},
 	}
 
 	if m.Read != nil {
@@ -247,24 +455,44 @@ func (m *Messaging) GetMessageSeenEvent() *MessageSeenEvent {
 	return messageSeenEvent
 }
 
-// MessageShareEvent defines message share events.
+// MessageShareEvent defines message share events,
+// including shared reels, posts and stories.
+// SharedPayloadURL holds the first shared url, Attachments every
+// attachment of the message along with its own type and payload,
+// ReplyToMID and ReplyToStory what the message replies to.
 type MessageShareEvent struct {
+	eventBase
 	Sender           *Sender
 	Recipient        *Recipient
 	Timestamp        time.Time
+	Type             WebhookEventType
+	MID              string
+	Text             string
 	SharedPayloadURL string
+	Attachments      []*Attachment
+	ReplyToMID       string
+	ReplyToStory     *ReplyToStory
 }
 
 // GetMessageShareEvent returns message share event.
-// Call this only when the event type is WebhookEventTypeShare.
+// Call this only when the event type is one of WebhookEventTypeShare,
+// WebhookEventTypeIGReel, WebhookEventTypeReel, WebhookEventTypeIGPost,
+// WebhookEventTypeStory, WebhookEventTypeFallback or WebhookEventTypeTemplate.
 func (m *Messaging) GetMessageShareEvent() *MessageShareEvent {
 	messageShareEvent := &MessageShareEvent{
+		Type:      m.Type,
 		Sender:    m.Sender,
 		Recipient: m.Recipient,
-		Timestamp: time.Unix(m.Timestamp, 0).UTC(),
+		Timestamp: m.timestamp(),
+		eventBase: eventBase{raw: m},
 	}
 
 	if m.Message != nil {
+		messageShareEvent.MID = m.Message.MID
+		messageShareEvent.Text = m.Message.Text
+		messageShareEvent.Attachments = m.Message.Attachments
+		messageShareEvent.ReplyToMID, messageShareEvent.ReplyToStory = m.Message.replyTo()
+
 		if len(m.Message.Attachments) > 0 {
 			messageShareEvent.SharedPayloadURL = m.Message.Attachments[0].Payload.URL
 		}
@@ -275,6 +503,7 @@ func (m *Messaging) GetMessageShareEvent() *MessageShareEvent {
 
 // MessageDeleteEvent defines message delete events.
 type MessageDeleteEvent struct {
+	eventBase
 	Sender     *Sender
 	Recipient  *Recipient
 	Timestamp  time.Time
@@ -287,7 +516,8 @@ func (m *Messaging) GetMessageDeleteEvent() *MessageDeleteEvent {
 	messageDeletevent := &MessageDeleteEvent{
 		Sender:    m.Sender,
 		Recipient: m.Recipient,
-		Timestamp: time.Unix(m.Timestamp, 0).UTC(),
+		Timestamp: m.timestamp(),
+		eventBase: eventBase{raw: m},
 	}
 
 	if m.Message != nil {
@@ -296,3 +526,111 @@ func (m *Messaging) GetMessageDeleteEvent() *MessageDeleteEvent {
 
 	return messageDeletevent
 }
+
+// EchoEvent defines flatten echo event of a message sent by the
+// instagram account, either by this app, another app or a human
+// from the instagram inbox. Sender is the instagram account and
+// Recipient the user the message was sent to.
+type EchoEvent struct {
+	eventBase
+	Sender       *Sender
+	Recipient    *Recipient
+	Timestamp    time.Time
+	MID          string
+	Text         string
+	AppID        AppID
+	Metadata     string
+	ReplyToMID   string
+	Attachments  []*Attachment
+	Templates    []*AttachmentPayload
+	QuickReplies []*WebhookEchoQuickReply
+}
+
+// GetEchoEvent returns echo event.
+// Call this only when the event type is WebhookEventTypeEcho.
+func (m *Messaging) GetEchoEvent() *EchoEvent {
+	echoEvent := &EchoEvent{
+		Sender:    m.Sender,
+		Recipient: m.Recipient,
+		Timestamp: m.timestamp(),
+		eventBase: eventBase{raw: m},
+	}
+
+	if m.Message != nil {
+		echoEvent.MID = m.Message.MID
+		echoEvent.Text = m.Message.Text
+		echoEvent.AppID = m.Message.AppID
+		echoEvent.Metadata = m.Message.Metadata
+		echoEvent.Attachments = m.Message.Attachments
+		echoEvent.QuickReplies = m.Message.QuickReplies
+
+		if m.Message.ReplyTo != nil {
+			echoEvent.ReplyToMID = m.Message.ReplyTo.MID
+		}
+
+		for _, attachment := range m.Message.Attachments {
+			if attachment != nil && attachment.EventType() == WebhookEventTypeTemplate {
+				echoEvent.Templates = append(echoEvent.Templates, &attachment.Payload)
+			}
+		}
+	}
+
+	return echoEvent
+}
+
+// IsSentByApp reports whether the echoed message was sent by the app
+// of the given id. Messages sent by a human from the instagram inbox
+// or by another app carry a different app id, if any.
+func (e *EchoEvent) IsSentByApp(appID AppID) bool {
+	return e.AppID != "" && e.AppID == appID
+}
+
+// ReferralEvent defines flatten referral event.
+type ReferralEvent struct {
+	eventBase
+	Sender    *Sender
+	Recipient *Recipient
+	Timestamp time.Time
+	Ref       string
+	Source    string
+	Type      string
+	AdID      string
+	AdTitle   string
+	PhotoURL  string
+	VideoURL  string
+	PostID    string
+	ProductID string
+}
+
+// GetReferralEvent returns referral event.
+// Call this when the event type is WebhookEventTypeReferral,
+// or when HasReferral reports a referral nested inside a message or postback.
+func (m *Messaging) GetReferralEvent() *ReferralEvent {
+	referralEvent := &ReferralEvent{
+		Sender:    m.Sender,
+		Recipient: m.Recipient,
+		Timestamp: m.timestamp(),
+		eventBase: eventBase{raw: m},
+	}
+
+	if referral := m.referral(); referral != nil {
+		referralEvent.Ref = referral.Ref
+		referralEvent.Source = referral.Source
+		referralEvent.Type = referral.Type
+		referralEvent.AdID = referral.AdID
+		referralEvent.ProductID = referral.Product.ID
+
+		if ads := referral.AdsContextData; ads != nil {
+			referralEvent.AdTitle = ads.AdTitle
+			referralEvent.PhotoURL = ads.PhotoURL
+			referralEvent.VideoURL = ads.VideoURL
+			referralEvent.PostID = ads.PostID
+
+			if referralEvent.ProductID == "" {
+				referralEvent.ProductID = ads.ProductID
+			}
+		}
+	}
+
+	return referralEvent
+}
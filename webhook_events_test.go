@@ -51,7 +51,7 @@ func TestGetQuickReplyEvent(t *testing.T) {
 				Recipient: &Recipient{
 					ID: "<IGID>",
 				},
-				Timestamp: time.Unix(1502905976377, 0).UTC(),
+				Timestamp: time.Unix(0, 1502905976377*int64(time.Millisecond)).UTC(),
 				MID:       "<MID>",
 				Text:      "<SOME_TEXT>",
 				Data: &WebhookQuickReply{
@@ -68,6 +68,7 @@ func TestGetQuickReplyEvent(t *testing.T) {
 			assert.NoError(t, err)
 
 			assert.Equal(t, WebhookEventTypeQuickReply, e.Entries[0].Messaging[0].Type)
+			tc.want.raw = e.Entries[0].Messaging[0]
 			assert.Equal(t, tc.want, e.Entries[0].Messaging[0].GetQuickReplyEvent())
 
 			if tc.afterEach != nil {
@@ -118,7 +119,7 @@ func TestGetPostbackEvent(t *testing.T) {
 				Recipient: &Recipient{
 					ID: "<IGID>",
 				},
-				Timestamp: time.Unix(1502905976377, 0).UTC(),
+				Timestamp: time.Unix(0, 1502905976377*int64(time.Millisecond)).UTC(),
 				Data: &Postback{
 					MID:     "<MESSAGE_ID>",
 					Title:   "<SELECTED_ICEBREAKER_QUESTION>",
@@ -135,6 +136,7 @@ func TestGetPostbackEvent(t *testing.T) {
 			assert.NoError(t, err)
 
 			assert.Equal(t, WebhookEventTypePostBack, e.Entries[0].Messaging[0].Type)
+			tc.want.raw = e.Entries[0].Messaging[0]
 			assert.Equal(t, tc.want, e.Entries[0].Messaging[0].GetPostBackEvent())
 
 			if tc.afterEach != nil {
@@ -190,7 +192,7 @@ func TestGetStoryReplyEvent(t *testing.T) {
 				Recipient: &Recipient{
 					ID: "<IGID>",
 				},
-				Timestamp: time.Unix(1569262485349, 0).UTC(),
+				Timestamp: time.Unix(0, 1569262485349*int64(time.Millisecond)).UTC(),
 				MID:       "<MESSAGE_ID>",
 				Text:      "<MESSAGE_CONTENT>",
 				Story: &ReplyToStory{
@@ -208,6 +210,7 @@ func TestGetStoryReplyEvent(t *testing.T) {
 			assert.NoError(t, err)
 
 			assert.Equal(t, WebhookEventTypeStoryReply, e.Entries[0].Messaging[0].Type)
+			tc.want.raw = e.Entries[0].Messaging[0]
 			assert.Equal(t, tc.want, e.Entries[0].Messaging[0].GetStoryReplyEvent())
 
 			if tc.afterEach != nil {
@@ -264,11 +267,80 @@ func TestGetStoryMentionEvent(t *testing.T) {
 				Recipient: &Recipient{
 					ID: "<IGID>",
 				},
-				Timestamp: time.Unix(1569262485349, 0).UTC(),
+				Timestamp: time.Unix(0, 1569262485349*int64(time.Millisecond)).UTC(),
 				MID:       "<MESSAGE_ID>",
 				Story: &ReplyToStory{
 					URL: "<CDN_URL>",
 				},
+				Attachments: []*Attachment{
+					{
+						Type: "story_mention",
+						Payload: AttachmentPayload{
+							URL: "<CDN_URL>",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "story mention replying to a message",
+			args: `{
+				"object":"instagram",
+				"entry":[
+				   {
+					  "id":"<IGID>",
+					  "time":1569262486134,
+					  "messaging":[
+						 {
+							"sender":{
+							   "id":"<IGSID>"
+							},
+							"recipient":{
+							   "id":"<IGID>"
+							},
+							"timestamp":1569262485349,
+							"message":{
+							   "mid":"<MESSAGE_ID>",
+							   "text":"<MESSAGE_CONTENT>",
+							   "reply_to":{
+								  "mid":"<REPLIED_MESSAGE_ID>"
+							   },
+							   "attachments":[
+								  {
+									 "type":"story_mention",
+									 "payload":{
+										"url":"<CDN_URL>"
+									 }
+								  }
+							   ]
+							}
+						 }
+					  ]
+				   }
+				]
+			}`,
+			want: &StoryMentionEvent{
+				Sender: &Sender{
+					ID: "<IGSID>",
+				},
+				Recipient: &Recipient{
+					ID: "<IGID>",
+				},
+				Timestamp: time.Unix(0, 1569262485349*int64(time.Millisecond)).UTC(),
+				MID:       "<MESSAGE_ID>",
+				Story: &ReplyToStory{
+					URL: "<CDN_URL>",
+				},
+				Attachments: []*Attachment{
+					{
+						Type: "story_mention",
+						Payload: AttachmentPayload{
+							URL: "<CDN_URL>",
+						},
+					},
+				},
+				Text:       "<MESSAGE_CONTENT>",
+				ReplyToMID: "<REPLIED_MESSAGE_ID>",
 			},
 		},
 	}
@@ -280,6 +352,7 @@ func TestGetStoryMentionEvent(t *testing.T) {
 			assert.NoError(t, err)
 
 			assert.Equal(t, WebhookEventTypeStoryMention, e.Entries[0].Messaging[0].Type)
+			tc.want.raw = e.Entries[0].Messaging[0]
 			assert.Equal(t, tc.want, e.Entries[0].Messaging[0].GetStoryMentionEvent())
 
 			if tc.afterEach != nil {
@@ -329,9 +402,63 @@ func TestGetTextMessageEvent(t *testing.T) {
 				Recipient: &Recipient{
 					ID: "<IGID>",
 				},
-				Timestamp: time.Unix(1569262485349, 0).UTC(),
+				Timestamp: time.Unix(0, 1569262485349*int64(time.Millisecond)).UTC(),
+				MID:       "<MESSAGE_ID>",
+				Text:      "<MESSAGE_CONTENT>",
+			},
+		},
+		{
+			name: "text message event with attachment of unknown type",
+			args: `{
+				"object": "instagram",
+				"entry": [
+				  {
+					"id": "<IGID>",
+					"time": 1569262486134,
+					"messaging": [
+					  {
+						"sender": {
+						  "id": "<IGSID>"
+						},
+						"recipient": {
+						  "id": "<IGID>"
+						},
+						"timestamp": 1569262485349,
+						"message": {
+						  "mid": "<MESSAGE_ID>",
+						  "text": "<MESSAGE_CONTENT>",
+						  "attachments": [
+							{
+							  "type": "unknown_new",
+							  "payload": {
+								"url": "<URL>"
+							  }
+							}
+						  ]
+						}
+					  }
+					]
+				  }
+				]
+			}`,
+			want: &TextMessageEvent{
+				Sender: &Sender{
+					ID: "<IGSID>",
+				},
+				Recipient: &Recipient{
+					ID: "<IGID>",
+				},
+				Timestamp: time.Unix(0, 1569262485349*int64(time.Millisecond)).UTC(),
 				MID:       "<MESSAGE_ID>",
 				Text:      "<MESSAGE_CONTENT>",
+				Attachments: []*Attachment{
+					{
+						Type: "unknown_new",
+						Payload: AttachmentPayload{
+							URL: "<URL>",
+						},
+					},
+				},
 			},
 		},
 	}
@@ -343,6 +470,7 @@ func TestGetTextMessageEvent(t *testing.T) {
 			assert.NoError(t, err)
 
 			assert.Equal(t, WebhookEventTypeTextMessage, e.Entries[0].Messaging[0].Type)
+			tc.want.raw = e.Entries[0].Messaging[0]
 			assert.Equal(t, tc.want, e.Entries[0].Messaging[0].GetTextMessageEvent())
 
 			if tc.afterEach != nil {
@@ -400,11 +528,19 @@ func TestGetMediaMessageEvent(t *testing.T) {
 				Recipient: &Recipient{
 					ID: "<IGID>",
 				},
-				Timestamp: time.Unix(1569262485349, 0).UTC(),
+				Timestamp: time.Unix(0, 1569262485349*int64(time.Millisecond)).UTC(),
 				MID:       "<MESSAGE_ID>",
 				Media: &AttachmentPayload{
 					URL: "<CDN_LINK>",
 				},
+				Attachments: []*Attachment{
+					{
+						Type: "image",
+						Payload: AttachmentPayload{
+							URL: "<CDN_LINK>",
+						},
+					},
+				},
 			},
 		},
 		{
@@ -448,11 +584,19 @@ func TestGetMediaMessageEvent(t *testing.T) {
 				Recipient: &Recipient{
 					ID: "<IGID>",
 				},
-				Timestamp: time.Unix(1569262485349, 0).UTC(),
+				Timestamp: time.Unix(0, 1569262485349*int64(time.Millisecond)).UTC(),
 				MID:       "<MESSAGE_ID>",
 				Media: &AttachmentPayload{
 					URL: "<CDN_LINK>",
 				},
+				Attachments: []*Attachment{
+					{
+						Type: "audio",
+						Payload: AttachmentPayload{
+							URL: "<CDN_LINK>",
+						},
+					},
+				},
 			},
 		},
 		{
@@ -496,11 +640,109 @@ func TestGetMediaMessageEvent(t *testing.T) {
 				Recipient: &Recipient{
 					ID: "<IGID>",
 				},
-				Timestamp: time.Unix(1569262485349, 0).UTC(),
+				Timestamp: time.Unix(0, 1569262485349*int64(time.Millisecond)).UTC(),
 				MID:       "<MESSAGE_ID>",
 				Media: &AttachmentPayload{
 					URL: "<CDN_LINK>",
 				},
+				Attachments: []*Attachment{
+					{
+						Type: "video",
+						Payload: AttachmentPayload{
+							URL: "<CDN_LINK>",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "multiple attachments with text and reply media message event",
+			args: `{
+				"object":"instagram",
+				"entry":[
+				   {
+					  "id":"<IGID>",
+					  "time":1569262486134,
+					  "messaging":[
+						 {
+							"sender":{
+							   "id":"<IGSID>"
+							},
+							"recipient":{
+							   "id":"<IGID>"
+							},
+							"timestamp":1569262485349,
+							"message":{
+							   "mid":"<MESSAGE_ID>",
+							   "text":"<MESSAGE_CONTENT>",
+							   "reply_to":{
+								  "mid":"<REPLIED_MESSAGE_ID>"
+							   },
+							   "attachments":[
+								  {
+									 "type":"image",
+									 "payload":{
+										"url":"<CDN_LINK_1>"
+									 }
+								  },
+								  {
+									 "type":"image",
+									 "payload":{
+										"url":"<CDN_LINK_2>"
+									 }
+								  },
+								  {
+									 "type":"video",
+									 "payload":{
+										"url":"<CDN_LINK_3>"
+									 }
+								  }
+							   ]
+							}
+						 }
+					  ]
+				   }
+				]
+			}`,
+			want: &MediaMessageEvent{
+				Type: WebhookEventTypeImageMessage,
+				Sender: &Sender{
+					ID: "<IGSID>",
+				},
+				Recipient: &Recipient{
+					ID: "<IGID>",
+				},
+				Timestamp: time.Unix(0, 1569262485349*int64(time.Millisecond)).UTC(),
+				MID:       "<MESSAGE_ID>",
+				Media: &AttachmentPayload{
+					URL: "<CDN_LINK_1>",
+				},
+				Attachments: []*Attachment{
+					{
+						Type: "image",
+						Payload: AttachmentPayload{
+							URL: "<CDN_LINK_1>",
+						},
+					},
+					{
+						Type: "image",
+						Payload: AttachmentPayload{
+							URL: "<CDN_LINK_2>",
+						},
+					},
+					{
+						Type: "video",
+						Payload: AttachmentPayload{
+							URL: "<CDN_LINK_3>",
+						},
+					},
+				},
+				Text:       "<MESSAGE_CONTENT>",
+				ReplyToMID: "<REPLIED_MESSAGE_ID>",
+			},
+			afterEach: func(t *testing.T, event *MediaMessageEvent) {
+				assert.Equal(t, WebhookEventTypeImageMessage, event.Attachments[1].EventType())
+				assert.Equal(t, WebhookEventTypeVideoMessage, event.Attachments[2].EventType())
 			},
 		},
 	}
@@ -511,6 +753,7 @@ func TestGetMediaMessageEvent(t *testing.T) {
 			err := json.Unmarshal([]byte(tc.args), e)
 			assert.NoError(t, err)
 
+			tc.want.raw = e.Entries[0].Messaging[0]
 			assert.Equal(t, tc.want, e.Entries[0].Messaging[0].GetMediaMessageEvent())
 
 			if tc.afterEach != nil {
@@ -563,7 +806,7 @@ func TestGetMessageReplyEvent(t *testing.T) {
 				Recipient: &Recipient{
 					ID: "<IGID>",
 				},
-				Timestamp:  time.Unix(1569262485349, 0).UTC(),
+				Timestamp:  time.Unix(0, 1569262485349*int64(time.Millisecond)).UTC(),
 				MID:        "<MESSAGE_ID>",
 				Text:       "<MESSAGE_CONTENT>",
 				ReplyToMID: "<MESSAGE_ID>",
@@ -578,6 +821,7 @@ func TestGetMessageReplyEvent(t *testing.T) {
 			assert.NoError(t, err)
 
 			assert.Equal(t, WebhookEventTypeMessageReply, e.Entries[0].Messaging[0].Type)
+			tc.want.raw = e.Entries[0].Messaging[0]
 			assert.Equal(t, tc.want, e.Entries[0].Messaging[0].GetMessageReplyEvent())
 
 			if tc.afterEach != nil {
@@ -629,7 +873,7 @@ func TestGetMessageReactionEvent(t *testing.T) {
 				Recipient: &Recipient{
 					ID: "<IGID>",
 				},
-				Timestamp: time.Unix(1569262485349, 0).UTC(),
+				Timestamp: time.Unix(0, 1569262485349*int64(time.Millisecond)).UTC(),
 				Reaction: &Reaction{
 					MID:      "<MID>",
 					Action:   "react",
@@ -647,6 +891,7 @@ func TestGetMessageReactionEvent(t *testing.T) {
 			assert.NoError(t, err)
 
 			assert.Equal(t, WebhookEventTypeReaction, e.Entries[0].Messaging[0].Type)
+			tc.want.raw = e.Entries[0].Messaging[0]
 			assert.Equal(t, tc.want, e.Entries[0].Messaging[0].GetMessageReactionEvent())
 
 			if tc.afterEach != nil {
@@ -695,7 +940,7 @@ func TestGetMessageSeenEvent(t *testing.T) {
 				Recipient: &Recipient{
 					ID: "<IGID>",
 				},
-				Timestamp: time.Unix(1569262485349, 0).UTC(),
+				Timestamp: time.Unix(0, 1569262485349*int64(time.Millisecond)).UTC(),
 				SeenMID:   "<LAST_MESSAGE_ID_READ>",
 			},
 		},
@@ -708,6 +953,7 @@ func TestGetMessageSeenEvent(t *testing.T) {
 			assert.NoError(t, err)
 
 			assert.Equal(t, WebhookEventTypeMessageSeen, e.Entries[0].Messaging[0].Type)
+			tc.want.raw = e.Entries[0].Messaging[0]
 			assert.Equal(t, tc.want, e.Entries[0].Messaging[0].GetMessageSeenEvent())
 
 			if tc.afterEach != nil {
@@ -758,43 +1004,28 @@ func TestGetMessageShareEvent(t *testing.T) {
 				]
 			}`,
 			want: &MessageShareEvent{
+				Type: WebhookEventTypeShare,
 				Sender: &Sender{
 					ID: "<IGSID>",
 				},
 				Recipient: &Recipient{
 					ID: "<IGID>",
 				},
-				Timestamp:        time.Unix(1569262485349, 0).UTC(),
+				Timestamp:        time.Unix(0, 1569262485349*int64(time.Millisecond)).UTC(),
+				MID:              "<MESSAGE_ID>",
 				SharedPayloadURL: "<CDN_URL>",
+				Attachments: []*Attachment{
+					{
+						Type: "share",
+						Payload: AttachmentPayload{
+							URL: "<CDN_URL>",
+						},
+					},
+				},
 			},
 		},
-	}
-
-	for _, tc := range testCases {
-		t.Run(tc.name, func(t *testing.T) {
-			e := new(WebhookEvent)
-			err := json.Unmarshal([]byte(tc.args), e)
-			assert.NoError(t, err)
-
-			assert.Equal(t, WebhookEventTypeShare, e.Entries[0].Messaging[0].Type)
-			assert.Equal(t, tc.want, e.Entries[0].Messaging[0].GetMessageShareEvent())
-
-			if tc.afterEach != nil {
-				tc.afterEach(t, e)
-			}
-		})
-	}
-}
-
-func TestGetMessageDeleteEvent(t *testing.T) {
-	testCases := []struct {
-		name      string
-		args      string
-		want      *MessageDeleteEvent
-		afterEach func(t *testing.T, event *WebhookEvent)
-	}{
 		{
-			name: "message delete event",
+			name: "ig reel share event",
 			args: `{
 				"object": "instagram",
 				"entry": [
@@ -809,41 +1040,889 @@ func TestGetMessageDeleteEvent(t *testing.T) {
 						"recipient": {
 						  "id": "<IGID>"
 						},
-						"timestamp": 1569262485349,
-						"message": {
+						"timestamp":1569262485349,
+						"message":{
 							"mid":"<MESSAGE_ID>",
-							"is_deleted": true
+							"attachments":[
+							   {
+								  "type":"ig_reel",
+								  "payload":{
+									 "url":"<CDN_URL>",
+									 "title":"<REEL_TITLE>",
+									 "reel_video_id":"<REEL_VIDEO_ID>"
+								  }
+							   }
+							]
 						}
 					  }
 					]
 				  }
 				]
 			}`,
-			want: &MessageDeleteEvent{
+			want: &MessageShareEvent{
+				Type: WebhookEventTypeIGReel,
 				Sender: &Sender{
 					ID: "<IGSID>",
 				},
 				Recipient: &Recipient{
 					ID: "<IGID>",
 				},
-				Timestamp:  time.Unix(1569262485349, 0).UTC(),
-				DeletedMID: "<MESSAGE_ID>",
+				Timestamp:        time.Unix(0, 1569262485349*int64(time.Millisecond)).UTC(),
+				MID:              "<MESSAGE_ID>",
+				SharedPayloadURL: "<CDN_URL>",
+				Attachments: []*Attachment{
+					{
+						Type: "ig_reel",
+						Payload: AttachmentPayload{
+							URL:         "<CDN_URL>",
+							Title:       "<REEL_TITLE>",
+							ReelVideoID: "<REEL_VIDEO_ID>",
+						},
+					},
+				},
 			},
 		},
-	}
-
-	for _, tc := range testCases {
-		t.Run(tc.name, func(t *testing.T) {
-			e := new(WebhookEvent)
-			err := json.Unmarshal([]byte(tc.args), e)
-			assert.NoError(t, err)
-
-			assert.Equal(t, WebhookEventTypeDeleted, e.Entries[0].Messaging[0].Type)
-			assert.Equal(t, tc.want, e.Entries[0].Messaging[0].GetMessageDeleteEvent())
-
-			if tc.afterEach != nil {
-				tc.afterEach(t, e)
-			}
-		})
-	}
+		{
+			name: "ig post share event",
+			args: `{
+				"object": "instagram",
+				"entry": [
+				  {
+					"id": "<IGID>",
+					"time": 1569262486134,
+					"messaging": [
+					  {
+						"sender": {
+						  "id": "<IGSID>"
+						},
+						"recipient": {
+						  "id": "<IGID>"
+						},
+						"timestamp":1569262485349,
+						"message":{
+							"mid":"<MESSAGE_ID>",
+							"attachments":[
+							   {
+								  "type":"ig_post",
+								  "payload":{
+									 "url":"<CDN_URL>",
+									 "title":"<POST_CAPTION>",
+									 "ig_post_media_id":"<IG_POST_MEDIA_ID>"
+								  }
+							   }
+							]
+						}
+					  }
+					]
+				  }
+				]
+			}`,
+			want: &MessageShareEvent{
+				Type: WebhookEventTypeIGPost,
+				Sender: &Sender{
+					ID: "<IGSID>",
+				},
+				Recipient: &Recipient{
+					ID: "<IGID>",
+				},
+				Timestamp:        time.Unix(0, 1569262485349*int64(time.Millisecond)).UTC(),
+				MID:              "<MESSAGE_ID>",
+				SharedPayloadURL: "<CDN_URL>",
+				Attachments: []*Attachment{
+					{
+						Type: "ig_post",
+						Payload: AttachmentPayload{
+							URL:           "<CDN_URL>",
+							Title:         "<POST_CAPTION>",
+							IGPostMediaID: "<IG_POST_MEDIA_ID>",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "story share event",
+			args: `{
+				"object": "instagram",
+				"entry": [
+				  {
+					"id": "<IGID>",
+					"time": 1569262486134,
+					"messaging": [
+					  {
+						"sender": {
+						  "id": "<IGSID>"
+						},
+						"recipient": {
+						  "id": "<IGID>"
+						},
+						"timestamp":1569262485349,
+						"message":{
+							"mid":"<MESSAGE_ID>",
+							"attachments":[
+							   {
+								  "type":"story",
+								  "payload":{
+									 "url":"<CDN_URL>",
+									 "id":"<STORY_ID>"
+								  }
+							   }
+							]
+						}
+					  }
+					]
+				  }
+				]
+			}`,
+			want: &MessageShareEvent{
+				Type: WebhookEventTypeStory,
+				Sender: &Sender{
+					ID: "<IGSID>",
+				},
+				Recipient: &Recipient{
+					ID: "<IGID>",
+				},
+				Timestamp:        time.Unix(0, 1569262485349*int64(time.Millisecond)).UTC(),
+				MID:              "<MESSAGE_ID>",
+				SharedPayloadURL: "<CDN_URL>",
+				Attachments: []*Attachment{
+					{
+						Type: "story",
+						Payload: AttachmentPayload{
+							URL: "<CDN_URL>",
+							ID:  "<STORY_ID>",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "fallback share event",
+			args: `{
+				"object": "instagram",
+				"entry": [
+				  {
+					"id": "<IGID>",
+					"time": 1569262486134,
+					"messaging": [
+					  {
+						"sender": {
+						  "id": "<IGSID>"
+						},
+						"recipient": {
+						  "id": "<IGID>"
+						},
+						"timestamp":1569262485349,
+						"message":{
+							"mid":"<MESSAGE_ID>",
+							"attachments":[
+							   {
+								  "type":"fallback",
+								  "payload":{
+									 "url":"<LINK_URL>",
+									 "title":"<LINK_TITLE>"
+								  }
+							   }
+							]
+						}
+					  }
+					]
+				  }
+				]
+			}`,
+			want: &MessageShareEvent{
+				Type: WebhookEventTypeFallback,
+				Sender: &Sender{
+					ID: "<IGSID>",
+				},
+				Recipient: &Recipient{
+					ID: "<IGID>",
+				},
+				Timestamp:        time.Unix(0, 1569262485349*int64(time.Millisecond)).UTC(),
+				MID:              "<MESSAGE_ID>",
+				SharedPayloadURL: "<LINK_URL>",
+				Attachments: []*Attachment{
+					{
+						Type: "fallback",
+						Payload: AttachmentPayload{
+							URL:   "<LINK_URL>",
+							Title: "<LINK_TITLE>",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "share replying to a story",
+			args: `{
+				"object": "instagram",
+				"entry": [
+				  {
+					"id": "<IGID>",
+					"time": 1569262486134,
+					"messaging": [
+					  {
+						"sender": {
+						  "id": "<IGSID>"
+						},
+						"recipient": {
+						  "id": "<IGID>"
+						},
+						"timestamp":1569262485349,
+						"message":{
+							"mid":"<MESSAGE_ID>",
+							"text":"<MESSAGE_CONTENT>",
+							"reply_to":{
+								"story":{
+									"url":"<STORY_URL>",
+									"id":"<STORY_ID>"
+								}
+							},
+							"attachments":[
+							   {
+								  "type":"share",
+								  "payload":{
+									 "url":"<SHARE_URL>"
+								  }
+							   }
+							]
+						}
+					  }
+					]
+				  }
+				]
+			}`,
+			want: &MessageShareEvent{
+				Type: WebhookEventTypeShare,
+				Sender: &Sender{
+					ID: "<IGSID>",
+				},
+				Recipient: &Recipient{
+					ID: "<IGID>",
+				},
+				Timestamp:        time.Unix(0, 1569262485349*int64(time.Millisecond)).UTC(),
+				MID:              "<MESSAGE_ID>",
+				Text:             "<MESSAGE_CONTENT>",
+				SharedPayloadURL: "<SHARE_URL>",
+				Attachments: []*Attachment{
+					{
+						Type: "share",
+						Payload: AttachmentPayload{
+							URL: "<SHARE_URL>",
+						},
+					},
+				},
+				ReplyToStory: &ReplyToStory{
+					URL: "<STORY_URL>",
+					ID:  "<STORY_ID>",
+				},
+			},
+		},
+		{
+			name: "share replying to a message",
+			args: `{
+				"object": "instagram",
+				"entry": [
+				  {
+					"id": "<IGID>",
+					"time": 1569262486134,
+					"messaging": [
+					  {
+						"sender": {
+						  "id": "<IGSID>"
+						},
+						"recipient": {
+						  "id": "<IGID>"
+						},
+						"timestamp":1569262485349,
+						"message":{
+							"mid":"<MESSAGE_ID>",
+							"reply_to":{
+								"mid":"<REPLIED_MESSAGE_ID>"
+							},
+							"attachments":[
+							   {
+								  "type":"share",
+								  "payload":{
+									 "url":"<SHARE_URL>"
+								  }
+							   }
+							]
+						}
+					  }
+					]
+				  }
+				]
+			}`,
+			want: &MessageShareEvent{
+				Type: WebhookEventTypeShare,
+				Sender: &Sender{
+					ID: "<IGSID>",
+				},
+				Recipient: &Recipient{
+					ID: "<IGID>",
+				},
+				Timestamp:        time.Unix(0, 1569262485349*int64(time.Millisecond)).UTC(),
+				MID:              "<MESSAGE_ID>",
+				SharedPayloadURL: "<SHARE_URL>",
+				Attachments: []*Attachment{
+					{
+						Type: "share",
+						Payload: AttachmentPayload{
+							URL: "<SHARE_URL>",
+						},
+					},
+				},
+				ReplyToMID: "<REPLIED_MESSAGE_ID>",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := new(WebhookEvent)
+			err := json.Unmarshal([]byte(tc.args), e)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tc.want.Type, e.Entries[0].Messaging[0].Type)
+			tc.want.raw = e.Entries[0].Messaging[0]
+			assert.Equal(t, tc.want, e.Entries[0].Messaging[0].GetMessageShareEvent())
+
+			if tc.afterEach != nil {
+				tc.afterEach(t, e)
+			}
+		})
+	}
+}
+
+func TestGetMessageDeleteEvent(t *testing.T) {
+	testCases := []struct {
+		name      string
+		args      string
+		want      *MessageDeleteEvent
+		afterEach func(t *testing.T, event *WebhookEvent)
+	}{
+		{
+			name: "message delete event",
+			args: `{
+				"object": "instagram",
+				"entry": [
+				  {
+					"id": "<IGID>",
+					"time": 1569262486134,
+					"messaging": [
+					  {
+						"sender": {
+						  "id": "<IGSID>"
+						},
+						"recipient": {
+						  "id": "<IGID>"
+						},
+						"timestamp": 1569262485349,
+						"message": {
+							"mid":"<MESSAGE_ID>",
+							"is_deleted": true
+						}
+					  }
+					]
+				  }
+				]
+			}`,
+			want: &MessageDeleteEvent{
+				Sender: &Sender{
+					ID: "<IGSID>",
+				},
+				Recipient: &Recipient{
+					ID: "<IGID>",
+				},
+				Timestamp:  time.Unix(0, 1569262485349*int64(time.Millisecond)).UTC(),
+				DeletedMID: "<MESSAGE_ID>",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := new(WebhookEvent)
+			err := json.Unmarshal([]byte(tc.args), e)
+			assert.NoError(t, err)
+
+			assert.Equal(t, WebhookEventTypeDeleted, e.Entries[0].Messaging[0].Type)
+			tc.want.raw = e.Entries[0].Messaging[0]
+			assert.Equal(t, tc.want, e.Entries[0].Messaging[0].GetMessageDeleteEvent())
+
+			if tc.afterEach != nil {
+				tc.afterEach(t, e)
+			}
+		})
+	}
+}
+
+func TestGetEchoEvent(t *testing.T) {
+	testCases := []struct {
+		name      string
+		args      string
+		want      *EchoEvent
+		afterEach func(t *testing.T, event *EchoEvent)
+	}{
+		{
+			name: "text echo event with quick replies",
+			args: `{
+				"object": "instagram",
+				"entry": [
+				  {
+					"id": "<IGID>",
+					"time": 1569262486134,
+					"messaging": [
+					  {
+						"sender": {
+						  "id": "<IGID>"
+						},
+						"recipient": {
+						  "id": "<IGSID>"
+						},
+						"timestamp": 1569262485349,
+						"message": {
+						  "mid": "<MESSAGE_ID>",
+						  "text": "<MESSAGE_CONTENT>",
+						  "is_echo": true,
+						  "app_id": 1517776481860111,
+						  "metadata": "<METADATA>",
+						  "quick_replies": [
+							{
+							  "content_type": "text",
+							  "title": "<TITLE>",
+							  "payload": "<PAYLOAD>"
+							}
+						  ]
+						}
+					  }
+					]
+				  }
+				]
+			}`,
+			want: &EchoEvent{
+				Sender: &Sender{
+					ID: "<IGID>",
+				},
+				Recipient: &Recipient{
+					ID: "<IGSID>",
+				},
+				Timestamp: time.Unix(0, 1569262485349*int64(time.Millisecond)).UTC(),
+				MID:       "<MESSAGE_ID>",
+				Text:      "<MESSAGE_CONTENT>",
+				AppID:     "1517776481860111",
+				Metadata:  "<METADATA>",
+				QuickReplies: []*WebhookEchoQuickReply{
+					{
+						ContentType: "text",
+						Title:       "<TITLE>",
+						Payload:     "<PAYLOAD>",
+					},
+				},
+			},
+			afterEach: func(t *testing.T, event *EchoEvent) {
+				assert.True(t, event.IsSentByApp("1517776481860111"))
+				assert.False(t, event.IsSentByApp("263902037430900"))
+			},
+		},
+		{
+			name: "template echo event sent from inbox",
+			args: `{
+				"object": "instagram",
+				"entry": [
+				  {
+					"id": "<IGID>",
+					"time": 1569262486134,
+					"messaging": [
+					  {
+						"sender": {
+						  "id": "<IGID>"
+						},
+						"recipient": {
+						  "id": "<IGSID>"
+						},
+						"timestamp": 1569262485349,
+						"message": {
+						  "mid": "<MESSAGE_ID>",
+						  "is_echo": true,
+						  "attachments": [
+							{
+							  "type": "template",
+							  "payload": {
+								"template_type": "generic",
+								"elements": [
+								  {
+									"title": "<TITLE>"
+								  }
+								]
+							  }
+							}
+						  ]
+						}
+					  }
+					]
+				  }
+				]
+			}`,
+			want: &EchoEvent{
+				Sender: &Sender{
+					ID: "<IGID>",
+				},
+				Recipient: &Recipient{
+					ID: "<IGSID>",
+				},
+				Timestamp: time.Unix(0, 1569262485349*int64(time.Millisecond)).UTC(),
+				MID:       "<MESSAGE_ID>",
+				Attachments: []*Attachment{
+					{
+						Type: "template",
+						Payload: AttachmentPayload{
+							TemplateType: "generic",
+							Elements: []*WebhookTemplateElement{
+								{
+									Title: "<TITLE>",
+								},
+							},
+						},
+					},
+				},
+				Templates: []*AttachmentPayload{
+					{
+						TemplateType: "generic",
+						Elements: []*WebhookTemplateElement{
+							{
+								Title: "<TITLE>",
+							},
+						},
+					},
+				},
+			},
+			afterEach: func(t *testing.T, event *EchoEvent) {
+				assert.False(t, event.IsSentByApp(""))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := new(WebhookEvent)
+			err := json.Unmarshal([]byte(tc.args), e)
+			assert.NoError(t, err)
+
+			assert.Equal(t, WebhookEventTypeEcho, e.Entries[0].Messaging[0].Type)
+
+			event := e.Entries[0].Messaging[0].GetEchoEvent()
+			tc.want.raw = e.Entries[0].Messaging[0]
+			assert.Equal(t, tc.want, event)
+
+			if tc.afterEach != nil {
+				tc.afterEach(t, event)
+			}
+		})
+	}
+}
+
+func TestGetReferralEvent(t *testing.T) {
+	testCases := []struct {
+		name      string
+		args      string
+		wantType  WebhookEventType
+		want      *ReferralEvent
+		afterEach func(t *testing.T, event *WebhookEvent)
+	}{
+		{
+			name: "ig.me link referral event",
+			args: `{
+				"object": "instagram",
+				"entry": [
+				  {
+					"id": "<IGID>",
+					"time": 1569262486134,
+					"messaging": [
+					  {
+						"sender": {
+						  "id": "<IGSID>"
+						},
+						"recipient": {
+						  "id": "<IGID>"
+						},
+						"timestamp": 1569262485349,
+						"referral": {
+							"ref": "<REF_DATA>",
+							"source": "IG_ME",
+							"type": "OPEN_THREAD"
+						}
+					  }
+					]
+				  }
+				]
+			}`,
+			wantType: WebhookEventTypeReferral,
+			want: &ReferralEvent{
+				Sender: &Sender{
+					ID: "<IGSID>",
+				},
+				Recipient: &Recipient{
+					ID: "<IGID>",
+				},
+				Timestamp: time.Unix(0, 1569262485349*int64(time.Millisecond)).UTC(),
+				Ref:       "<REF_DATA>",
+				Source:    "IG_ME",
+				Type:      "OPEN_THREAD",
+			},
+		},
+		{
+			name: "ad referral nested inside message",
+			args: `{
+				"object": "instagram",
+				"entry": [
+				  {
+					"id": "<IGID>",
+					"time": 1569262486134,
+					"messaging": [
+					  {
+						"sender": {
+						  "id": "<IGSID>"
+						},
+						"recipient": {
+						  "id": "<IGID>"
+						},
+						"timestamp": 1569262485349,
+						"message": {
+							"mid": "<MESSAGE_ID>",
+							"text": "<MESSAGE_CONTENT>",
+							"referral": {
+								"ref": "<REF_DATA>",
+								"ad_id": "<AD_ID>",
+								"source": "ADS",
+								"type": "OPEN_THREAD",
+								"ads_context_data": {
+									"ad_title": "<AD_TITLE>",
+									"photo_url": "<PHOTO_URL>",
+									"video_url": "<VIDEO_URL>",
+									"post_id": "<POST_ID>",
+									"product_id": "<PRODUCT_ID>"
+								}
+							}
+						}
+					  }
+					]
+				  }
+				]
+			}`,
+			wantType: WebhookEventTypeTextMessage,
+			want: &ReferralEvent{
+				Sender: &Sender{
+					ID: "<IGSID>",
+				},
+				Recipient: &Recipient{
+					ID: "<IGID>",
+				},
+				Timestamp: time.Unix(0, 1569262485349*int64(time.Millisecond)).UTC(),
+				Ref:       "<REF_DATA>",
+				Source:    "ADS",
+				Type:      "OPEN_THREAD",
+				AdID:      "<AD_ID>",
+				AdTitle:   "<AD_TITLE>",
+				PhotoURL:  "<PHOTO_URL>",
+				VideoURL:  "<VIDEO_URL>",
+				PostID:    "<POST_ID>",
+				ProductID: "<PRODUCT_ID>",
+			},
+			afterEach: func(t *testing.T, event *WebhookEvent) {
+				messaging := event.Entries[0].Messaging[0]
+				assert.Equal(t, "<MESSAGE_CONTENT>", messaging.GetTextMessageEvent().Text)
+			},
+		},
+		{
+			name: "product referral nested inside postback",
+			args: `{
+				"object": "instagram",
+				"entry": [
+				  {
+					"id": "<IGID>",
+					"time": 1569262486134,
+					"messaging": [
+					  {
+						"sender": {
+						  "id": "<IGSID>"
+						},
+						"recipient": {
+						  "id": "<IGID>"
+						},
+						"timestamp": 1569262485349,
+						"postback": {
+							"mid": "<MESSAGE_ID>",
+							"title": "<TITLE>",
+							"payload": "<PAYLOAD>",
+							"referral": {
+								"ref": "<REF_DATA>",
+								"source": "SHORTLINK",
+								"type": "OPEN_THREAD",
+								"product": {
+									"id": "<PRODUCT_ID>"
+								}
+							}
+						}
+					  }
+					]
+				  }
+				]
+			}`,
+			wantType: WebhookEventTypePostBack,
+			want: &ReferralEvent{
+				Sender: &Sender{
+					ID: "<IGSID>",
+				},
+				Recipient: &Recipient{
+					ID: "<IGID>",
+				},
+				Timestamp: time.Unix(0, 1569262485349*int64(time.Millisecond)).UTC(),
+				Ref:       "<REF_DATA>",
+				Source:    "SHORTLINK",
+				Type:      "OPEN_THREAD",
+				ProductID: "<PRODUCT_ID>",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := new(WebhookEvent)
+			err := json.Unmarshal([]byte(tc.args), e)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tc.wantType, e.Entries[0].Messaging[0].Type)
+			assert.True(t, e.Entries[0].Messaging[0].HasReferral())
+			tc.want.raw = e.Entries[0].Messaging[0]
+			assert.Equal(t, tc.want, e.Entries[0].Messaging[0].GetReferralEvent())
+
+			if tc.afterEach != nil {
+				tc.afterEach(t, e)
+			}
+		})
+	}
+}
+
+func TestMessagingEvent(t *testing.T) {
+	sender := &Sender{ID: "<IGSID>"}
+	recipient := &Recipient{ID: "<IGID>"}
+	timestamp := time.Date(2019, time.September, 23, 18, 14, 45, 349000000, time.UTC)
+
+	testCases := []struct {
+		name      string
+		args      *Messaging
+		wantEvent Event
+		wantMID   string
+	}{
+		{
+			name: "text message event",
+			args: &Messaging{
+				Message: &WebhookMessage{MID: "<MESSAGE_ID>", Text: "<MESSAGE_CONTENT>"},
+			},
+			wantEvent: &TextMessageEvent{},
+			wantMID:   "<MESSAGE_ID>",
+		},
+		{
+			name: "media message event",
+			args: &Messaging{
+				Message: &WebhookMessage{
+					MID:         "<MESSAGE_ID>",
+					Attachments: []*Attachment{{Type: "image"}},
+				},
+			},
+			wantEvent: &MediaMessageEvent{},
+			wantMID:   "<MESSAGE_ID>",
+		},
+		{
+			name: "reel share event",
+			args: &Messaging{
+				Message: &WebhookMessage{
+					MID:         "<MESSAGE_ID>",
+					Attachments: []*Attachment{{Type: "ig_reel"}},
+				},
+			},
+			wantEvent: &MessageShareEvent{},
+			wantMID:   "<MESSAGE_ID>",
+		},
+		{
+			name: "template share event",
+			args: &Messaging{
+				Message: &WebhookMessage{
+					MID:         "<MESSAGE_ID>",
+					Attachments: []*Attachment{{Type: "template"}},
+				},
+			},
+			wantEvent: &MessageShareEvent{},
+			wantMID:   "<MESSAGE_ID>",
+		},
+		{
+			name: "postback event",
+			args: &Messaging{
+				PostBack: &Postback{MID: "<MESSAGE_ID>"},
+			},
+			wantEvent: &PostBackEvent{},
+			wantMID:   "<MESSAGE_ID>",
+		},
+		{
+			name: "message seen event",
+			args: &Messaging{
+				Read: &Read{MID: "<MESSAGE_ID>"},
+			},
+			wantEvent: &MessageSeenEvent{},
+		},
+		{
+			name: "take thread control event",
+			args: &Messaging{
+				TakeThreadControl: &TakeThreadControl{},
+			},
+			wantEvent: &TakeThreadControlEvent{},
+		},
+		{
+			name: "unsupported message event",
+			args: &Messaging{
+				Message: &WebhookMessage{MID: "<MESSAGE_ID>", IsUnsupported: true},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := tc.args
+			m.Sender = sender
+			m.Recipient = recipient
+			m.Timestamp = 1569262485349
+			m.setType()
+
+			event := m.Event()
+			if tc.wantEvent == nil {
+				assert.Nil(t, event)
+
+				return
+			}
+
+			assert.IsType(t, tc.wantEvent, event)
+			assert.Equal(t, m.Type, event.GetType())
+			assert.Equal(t, sender, event.GetSender())
+			assert.Equal(t, recipient, event.GetRecipient())
+			assert.Equal(t, timestamp, event.GetTimestamp())
+			assert.Equal(t, tc.wantMID, event.GetMID())
+			assert.Same(t, m, event.GetRaw())
+		})
+	}
+}
+
+func TestGetEventWithoutMessage(t *testing.T) {
+	m := &Messaging{Timestamp: 1569262485349}
+
+	assert.NotPanics(t, func() {
+		assert.Equal(t, "", m.GetTextMessageEvent().Text)
+		assert.Nil(t, m.GetStoryMentionEvent().Story)
+	})
+
+	var e TextMessageEvent
+	assert.Nil(t, e.GetRaw())
+	assert.Equal(t, WebhookEventType(""), e.GetType())
+	assert.True(t, e.GetTimestamp().IsZero())
 }
@@ -0,0 +1,272 @@
+package instabot
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"hash"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"strings"
+)
+
+// webhook signature headers.
+// https://developers.facebook.com/docs/messenger-platform/webhooks#validate-payloads
+const (
+	HeaderHubSignature256 = "X-Hub-Signature-256"
+	HeaderHubSignature    = "X-Hub-Signature"
+)
+
+// webhook subscription verification query parameters.
+// https://developers.facebook.com/docs/graph-api/webhooks/getting-started#verification-requests
+const (
+	QueryHubMode        = "hub.mode"
+	QueryHubVerifyToken = "hub.verify_token"
+	QueryHubChallenge   = "hub.challenge"
+
+	HubModeSubscribe = "subscribe"
+)
+
+// DefaultWebhookMaxBodySize is the default maximum accepted webhook payload size.
+const DefaultWebhookMaxBodySize int64 = 1 << 20
+
+// WebhookEventHandler handles decoded webhook events.
+type WebhookEventHandler interface {
+	HandleWebhookEvent(ctx context.Context, event *WebhookEvent) error
+}
+
+// WebhookEventHandlerFunc is an adapter to allow the use of
+// ordinary functions as WebhookEventHandler.
+type WebhookEventHandlerFunc func(ctx context.Context, event *WebhookEvent) error
+
+// HandleWebhookEvent calls f(ctx, event).
+func (f WebhookEventHandlerFunc) HandleWebhookEvent(ctx context.Context, event *WebhookEvent) error {
+	return f(ctx, event)
+}
+
+// VerifyTokenFunc reports whether the verify token of a
+// subscription verification request is valid.
+type VerifyTokenFunc func(verifyToken string) bool
+
+// WebhookHandler defines http.Handler serving instagram webhook deliveries
+// and webhook subscription verification requests.
+type WebhookHandler struct {
+	appSecret   []byte
+	handler     WebhookEventHandler
+	maxBodySize int64
+	verifyToken VerifyTokenFunc
+	recorder    *Recorder
+	recordErr   func(r *http.Request, err error)
+	decodeOpts  []DecodeOption
+}
+
+// WebhookHandlerOption defines optional argument for new webhook handler construction.
+type WebhookHandlerOption func(*WebhookHandler) error
+
+// WithMaxBodySize sets maximum accepted webhook payload size in bytes.
+func WithMaxBodySize(size int64) WebhookHandlerOption {
+	return func(h *WebhookHandler) error {
+		if size <= 0 {
+			return ErrInvalidMaxBodySize
+		}
+
+		h.maxBodySize = size
+
+		return nil
+	}
+}
+
+// WithVerifyToken sets token used to answer
+// webhook subscription verification requests.
+func WithVerifyToken(verifyToken string) WebhookHandlerOption {
+	return func(h *WebhookHandler) error {
+		if verifyToken == "" {
+			return ErrMissingVerifyToken
+		}
+
+		h.verifyToken = func(token string) bool {
+			return subtle.ConstantTimeCompare([]byte(token), []byte(verifyToken)) == 1
+		}
+
+		return nil
+	}
+}
+
+// WithVerifier sets verify token verifier used to answer
+// webhook subscription verification requests.
+func WithVerifier(verifier VerifyTokenFunc) WebhookHandlerOption {
+	return func(h *WebhookHandler) error {
+		if verifier == nil {
+			return ErrMissingVerifyToken
+		}
+
+		h.verifyToken = verifier
+
+		return nil
+	}
+}
+
+// WithDecodeOptions sets options used to decode deliveries,
+// deliveries failing to be decoded are answered with bad request.
+func WithDecodeOptions(options ...DecodeOption) WebhookHandlerOption {
+	return func(h *WebhookHandler) error {
+		h.decodeOpts = options
+
+		return nil
+	}
+}
+
+// NewWebhookHandler returns a new webhook http handler.
+// Every delivery is verified against the app secret before
+// being decoded and passed to handler.
+// https://developers.facebook.com/docs/messenger-platform/instagram/features/webhook
+func NewWebhookHandler(appSecret string, handler WebhookEventHandler, options ...WebhookHandlerOption) (*WebhookHandler, error) {
+	if appSecret == "" {
+		return nil, ErrMissingAppSecret
+	}
+
+	if handler == nil {
+		return nil, ErrMissingWebhookEventHandler
+	}
+
+	h := &WebhookHandler{
+		appSecret:   []byte(appSecret),
+		handler:     handler,
+		maxBodySize: DefaultWebhookMaxBodySize,
+	}
+
+	for _, option := range options {
+		if err := option(h); err != nil {
+			return nil, err
+		}
+	}
+
+	return h, nil
+}
+
+// ServeHTTP implements http.Handler.
+// GET requests are treated as subscription verification requests,
+// POST requests as event deliveries.
+func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.serveVerification(w, r)
+	case http.MethodPost:
+		h.serveDelivery(w, r)
+	default:
+		w.Header().Set("Allow", strings.Join([]string{http.MethodGet, http.MethodPost}, ", "))
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+// serveVerification answers webhook subscription verification handshake.
+// https://developers.facebook.com/docs/graph-api/webhooks/getting-started#verification-requests
+func (h *WebhookHandler) serveVerification(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	if h.verifyToken == nil ||
+		query.Get(QueryHubMode) != HubModeSubscribe ||
+		!h.verifyToken(query.Get(QueryHubVerifyToken)) {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(query.Get(QueryHubChallenge)))
+}
+
+func (h *WebhookHandler) serveDelivery(w http.ResponseWriter, r *http.Request) {
+	// read one byte past the limit to tell oversized bodies from read errors.
+	limit := h.maxBodySize
+	if limit < math.MaxInt64 {
+		limit++
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, limit))
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+
+		return
+	}
+
+	if int64(len(body)) > h.maxBodySize {
+		http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+
+		return
+	}
+
+	if err := VerifySignature(h.appSecret, body, r.Header); err != nil {
+		if err == ErrMissingSignature {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+
+			return
+		}
+
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+
+		return
+	}
+
+	if h.recorder != nil {
+		if err := h.recorder.Record(r.Header, body); err != nil {
+			h.recordError(r, err)
+		}
+	}
+
+	event, err := DecodeWebhookEvent(body, h.decodeOpts...)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+
+		return
+	}
+
+	if err := h.handler.HandleWebhookEvent(r.Context(), event); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// VerifySignature verifies payload signature sent by instagram.
+// X-Hub-Signature-256 is preferred, legacy X-Hub-Signature is
+// used only when the former is absent.
+func VerifySignature(appSecret []byte, payload []byte, header http.Header) error {
+	if signature := header.Get(HeaderHubSignature256); signature != "" {
+		return verifySignature(sha256.New, "sha256=", appSecret, payload, signature)
+	}
+
+	if signature := header.Get(HeaderHubSignature); signature != "" {
+		return verifySignature(sha1.New, "sha1=", appSecret, payload, signature)
+	}
+
+	return ErrMissingSignature
+}
+
+func verifySignature(fn func() hash.Hash, prefix string, appSecret []byte, payload []byte, signature string) error {
+	if !strings.HasPrefix(signature, prefix) {
+		return ErrInvalidSignature
+	}
+
+	got, err := hex.DecodeString(strings.TrimPrefix(signature, prefix))
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	mac := hmac.New(fn, appSecret)
+	mac.Write(payload)
+
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
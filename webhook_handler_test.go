@@ -0,0 +1,384 @@
+package instabot
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func signPayload(appSecret string, payload string) string {
+	mac := hmac.New(sha256.New, []byte(appSecret))
+	mac.Write([]byte(payload))
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func signPayloadSHA1(appSecret string, payload string) string {
+	mac := hmac.New(sha1.New, []byte(appSecret))
+	mac.Write([]byte(payload))
+
+	return "sha1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestNewWebhookHandler(t *testing.T) {
+	handler := WebhookEventHandlerFunc(func(ctx context.Context, event *WebhookEvent) error {
+		return nil
+	})
+
+	testCases := []struct {
+		name      string
+		appSecret string
+		handler   WebhookEventHandler
+		options   []WebhookHandlerOption
+		wantErr   error
+	}{
+		{
+			name:    "it should return error, when app secret is not given",
+			handler: handler,
+			wantErr: ErrMissingAppSecret,
+		},
+		{
+			name:      "it should return error, when event handler is not given",
+			appSecret: "app_secret",
+			wantErr:   ErrMissingWebhookEventHandler,
+		},
+		{
+			name:      "it should return error, when max body size is invalid",
+			appSecret: "app_secret",
+			handler:   handler,
+			options:   []WebhookHandlerOption{WithMaxBodySize(0)},
+			wantErr:   ErrInvalidMaxBodySize,
+		},
+		{
+			name:      "it should return error, when verify token is empty",
+			appSecret: "app_secret",
+			handler:   handler,
+			options:   []WebhookHandlerOption{WithVerifyToken("")},
+			wantErr:   ErrMissingVerifyToken,
+		},
+		{
+			name:      "it should return error, when verifier is nil",
+			appSecret: "app_secret",
+			handler:   handler,
+			options:   []WebhookHandlerOption{WithVerifier(nil)},
+			wantErr:   ErrMissingVerifyToken,
+		},
+		{
+			name:      "it should return webhook handler",
+			appSecret: "app_secret",
+			handler:   handler,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h, err := NewWebhookHandler(tc.appSecret, tc.handler, tc.options...)
+			if tc.wantErr != nil {
+				assert.Equal(t, tc.wantErr, err)
+				assert.Nil(t, h)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, h)
+			}
+		})
+	}
+}
+
+func TestWebhookHandlerServeHTTP(t *testing.T) {
+	appSecret := "app_secret"
+	payload := `{
+		"object": "instagram",
+		"entry": [
+		  {
+			"id": "<IGID>",
+			"time": 1569262486134,
+			"messaging": [
+			  {
+				"sender": {
+				  "id": "<IGSID>"
+				},
+				"recipient": {
+				  "id": "<IGID>"
+				},
+				"timestamp": 1569262485349,
+				"message": {
+				  "mid": "<MESSAGE_ID>",
+				  "text": "<MESSAGE_CONTENT>"
+				}
+			  }
+			]
+		  }
+		]
+	}`
+
+	unknownFieldPayload := strings.Replace(payload, `"text": "<MESSAGE_CONTENT>"`, `"text": "<MESSAGE_CONTENT>", "new_field": true`, 1)
+
+	testCases := []struct {
+		name          string
+		method        string
+		body          string
+		header        map[string]string
+		handlerErr    error
+		maxBodySize   int64
+		failRead      bool
+		decodeOpts    []DecodeOption
+		wantCode      int
+		wantDelivered bool
+	}{
+		{
+			name:   "it should deliver event, when sha256 signature is valid",
+			method: http.MethodPost,
+			body:   payload,
+			header: map[string]string{
+				HeaderHubSignature256: signPayload(appSecret, payload),
+			},
+			wantCode:      http.StatusOK,
+			wantDelivered: true,
+		},
+		{
+			name:   "it should deliver event, when legacy sha1 signature is valid",
+			method: http.MethodPost,
+			body:   payload,
+			header: map[string]string{
+				HeaderHubSignature: signPayloadSHA1(appSecret, payload),
+			},
+			wantCode:      http.StatusOK,
+			wantDelivered: true,
+		},
+		{
+			name:     "it should reject, when signature is missing",
+			method:   http.MethodPost,
+			body:     payload,
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:   "it should reject, when payload is tampered",
+			method: http.MethodPost,
+			body:   strings.Replace(payload, "<MESSAGE_CONTENT>", "<TAMPERED>", 1),
+			header: map[string]string{
+				HeaderHubSignature256: signPayload(appSecret, payload),
+			},
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:   "it should reject, when signed with another secret",
+			method: http.MethodPost,
+			body:   payload,
+			header: map[string]string{
+				HeaderHubSignature256: signPayload("another_secret", payload),
+			},
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:   "it should reject, when sha256 signature is invalid even if sha1 is valid",
+			method: http.MethodPost,
+			body:   payload,
+			header: map[string]string{
+				HeaderHubSignature256: "sha256=invalid",
+				HeaderHubSignature:    signPayloadSHA1(appSecret, payload),
+			},
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:   "it should reject, when signature prefix is wrong",
+			method: http.MethodPost,
+			body:   payload,
+			header: map[string]string{
+				HeaderHubSignature256: strings.Replace(signPayload(appSecret, payload), "sha256=", "sha1=", 1),
+			},
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:   "it should reject, when payload is not json",
+			method: http.MethodPost,
+			body:   "not json",
+			header: map[string]string{
+				HeaderHubSignature256: signPayload(appSecret, "not json"),
+			},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:   "it should reject, when payload is too large",
+			method: http.MethodPost,
+			body:   payload,
+			header: map[string]string{
+				HeaderHubSignature256: signPayload(appSecret, payload),
+			},
+			maxBodySize: 10,
+			wantCode:    http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:   "it should respond bad request, when body read fails",
+			method: http.MethodPost,
+			body:   payload,
+			header: map[string]string{
+				HeaderHubSignature256: signPayload(appSecret, payload),
+			},
+			failRead: true,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:   "it should return server error, when event handler fails",
+			method: http.MethodPost,
+			body:   payload,
+			header: map[string]string{
+				HeaderHubSignature256: signPayload(appSecret, payload),
+			},
+			handlerErr:    errors.New("handler error"),
+			wantCode:      http.StatusInternalServerError,
+			wantDelivered: true,
+		},
+		{
+			name:     "it should reject, when method is not allowed",
+			method:   http.MethodPut,
+			body:     payload,
+			wantCode: http.StatusMethodNotAllowed,
+		},
+		{
+			name:   "it should respond bad request, when strictly decoding unknown fields",
+			method: http.MethodPost,
+			body:   unknownFieldPayload,
+			header: map[string]string{
+				HeaderHubSignature256: signPayload(appSecret, unknownFieldPayload),
+			},
+			decodeOpts: []DecodeOption{StrictDecoding()},
+			wantCode:   http.StatusBadRequest,
+		},
+		{
+			name:   "it should deliver event with unknown fields, when not strictly decoding",
+			method: http.MethodPost,
+			body:   unknownFieldPayload,
+			header: map[string]string{
+				HeaderHubSignature256: signPayload(appSecret, unknownFieldPayload),
+			},
+			wantCode:      http.StatusOK,
+			wantDelivered: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var delivered *WebhookEvent
+
+			options := []WebhookHandlerOption{}
+			if tc.maxBodySize > 0 {
+				options = append(options, WithMaxBodySize(tc.maxBodySize))
+			}
+
+			if tc.decodeOpts != nil {
+				options = append(options, WithDecodeOptions(tc.decodeOpts...))
+			}
+
+			h, err := NewWebhookHandler(
+				appSecret,
+				WebhookEventHandlerFunc(func(ctx context.Context, event *WebhookEvent) error {
+					delivered = event
+
+					return tc.handlerErr
+				}),
+				options...,
+			)
+			assert.NoError(t, err)
+
+			var body io.Reader = strings.NewReader(tc.body)
+			if tc.failRead {
+				body = iotest.TimeoutReader(body)
+			}
+
+			req := httptest.NewRequest(tc.method, "/webhook", body)
+			for k, v := range tc.header {
+				req.Header.Set(k, v)
+			}
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.wantCode, rec.Code)
+
+			if tc.wantDelivered {
+				assert.NotNil(t, delivered)
+				assert.Equal(t, WebhookEventTypeTextMessage, delivered.Entries[0].Messaging[0].Type)
+			} else {
+				assert.Nil(t, delivered)
+			}
+		})
+	}
+}
+
+func TestWebhookHandlerVerification(t *testing.T) {
+	appSecret := "app_secret"
+	verifyToken := "verify_token"
+	handler := WebhookEventHandlerFunc(func(ctx context.Context, event *WebhookEvent) error {
+		return nil
+	})
+
+	testCases := []struct {
+		name     string
+		options  []WebhookHandlerOption
+		query    string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "it should return challenge, when verify token matches",
+			options:  []WebhookHandlerOption{WithVerifyToken(verifyToken)},
+			query:    "hub.mode=subscribe&hub.verify_token=verify_token&hub.challenge=1158201444",
+			wantCode: http.StatusOK,
+			wantBody: "1158201444",
+		},
+		{
+			name: "it should return challenge, when verifier accepts token",
+			options: []WebhookHandlerOption{WithVerifier(func(token string) bool {
+				return token == "rotated_token"
+			})},
+			query:    "hub.mode=subscribe&hub.verify_token=rotated_token&hub.challenge=1158201444",
+			wantCode: http.StatusOK,
+			wantBody: "1158201444",
+		},
+		{
+			name:     "it should reject, when verify token does not match",
+			options:  []WebhookHandlerOption{WithVerifyToken(verifyToken)},
+			query:    "hub.mode=subscribe&hub.verify_token=wrong_token&hub.challenge=1158201444",
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "it should reject, when mode is not subscribe",
+			options:  []WebhookHandlerOption{WithVerifyToken(verifyToken)},
+			query:    "hub.mode=unsubscribe&hub.verify_token=verify_token&hub.challenge=1158201444",
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "it should reject, when verify token is not configured",
+			query:    "hub.mode=subscribe&hub.verify_token=verify_token&hub.challenge=1158201444",
+			wantCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h, err := NewWebhookHandler(appSecret, handler, tc.options...)
+			assert.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "/webhook?"+tc.query, nil)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.wantCode, rec.Code)
+
+			if tc.wantBody != "" {
+				assert.Equal(t, tc.wantBody, rec.Body.String())
+			}
+		})
+	}
+}
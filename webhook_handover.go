@@ -0,0 +1,139 @@
+package instabot
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AppID defines facebook app id, delivered either as
+// a JSON string or a JSON number.
+// String app ids are kept as delivered, even when empty or not numeric.
+type AppID string
+
+// UnmarshalJSON unmarshal app id from JSON string or number.
+func (id *AppID) UnmarshalJSON(buffer []byte) error {
+	var s string
+	if err := json.Unmarshal(buffer, &s); err == nil {
+		*id = AppID(s)
+
+		return nil
+	}
+
+	var n json.Number
+	if err := json.Unmarshal(buffer, &n); err != nil {
+		return err
+	}
+
+	*id = AppID(n)
+
+	return nil
+}
+
+// PassThreadControl defines thread control passed to the app.
+// https://developers.facebook.com/docs/messenger-platform/instagram/features/handover-protocol
+type PassThreadControl struct {
+	NewOwnerAppID      AppID  `json:"new_owner_app_id"`
+	PreviousOwnerAppID AppID  `json:"previous_owner_app_id"`
+	Metadata           string `json:"metadata"`
+}
+
+// TakeThreadControl defines thread control taken from the app.
+type TakeThreadControl struct {
+	PreviousOwnerAppID AppID  `json:"previous_owner_app_id"`
+	NewOwnerAppID      AppID  `json:"new_owner_app_id"`
+	Metadata           string `json:"metadata"`
+}
+
+// RequestThreadControl defines thread control requested from the app.
+type RequestThreadControl struct {
+	RequestedOwnerAppID AppID  `json:"requested_owner_app_id"`
+	Metadata            string `json:"metadata"`
+}
+
+// PassThreadControlEvent defines flatten pass thread control event.
+type PassThreadControlEvent struct {
+	eventBase
+	Sender             *Sender
+	Recipient          *Recipient
+	Timestamp          time.Time
+	NewOwnerAppID      AppID
+	PreviousOwnerAppID AppID
+	Metadata           string
+}
+
+// GetPassThreadControlEvent returns pass thread control event.
+// Call this only when the event type is WebhookEventTypePassThreadControl.
+func (m *Messaging) GetPassThreadControlEvent() *PassThreadControlEvent {
+	passThreadControlEvent := &PassThreadControlEvent{
+		Sender:    m.Sender,
+		Recipient: m.Recipient,
+		Timestamp: m.timestamp(),
+		eventBase: eventBase{raw: m},
+	}
+
+	if m.PassThreadControl != nil {
+		passThreadControlEvent.NewOwnerAppID = m.PassThreadControl.NewOwnerAppID
+		passThreadControlEvent.PreviousOwnerAppID = m.PassThreadControl.PreviousOwnerAppID
+		passThreadControlEvent.Metadata = m.PassThreadControl.Metadata
+	}
+
+	return passThreadControlEvent
+}
+
+// TakeThreadControlEvent defines flatten take thread control event.
+type TakeThreadControlEvent struct {
+	eventBase
+	Sender             *Sender
+	Recipient          *Recipient
+	Timestamp          time.Time
+	PreviousOwnerAppID AppID
+	NewOwnerAppID      AppID
+	Metadata           string
+}
+
+// GetTakeThreadControlEvent returns take thread control event.
+// Call this only when the event type is WebhookEventTypeTakeThreadControl.
+func (m *Messaging) GetTakeThreadControlEvent() *TakeThreadControlEvent {
+	takeThreadControlEvent := &TakeThreadControlEvent{
+		Sender:    m.Sender,
+		Recipient: m.Recipient,
+		Timestamp: m.timestamp(),
+		eventBase: eventBase{raw: m},
+	}
+
+	if m.TakeThreadControl != nil {
+		takeThreadControlEvent.PreviousOwnerAppID = m.TakeThreadControl.PreviousOwnerAppID
+		takeThreadControlEvent.NewOwnerAppID = m.TakeThreadControl.NewOwnerAppID
+		takeThreadControlEvent.Metadata = m.TakeThreadControl.Metadata
+	}
+
+	return takeThreadControlEvent
+}
+
+// RequestThreadControlEvent defines flatten request thread control event.
+type RequestThreadControlEvent struct {
+	eventBase
+	Sender              *Sender
+	Recipient           *Recipient
+	Timestamp           time.Time
+	RequestedOwnerAppID AppID
+	Metadata            string
+}
+
+// GetRequestThreadControlEvent returns request thread control event.
+// Call this only when the event type is WebhookEventTypeRequestThreadControl.
+func (m *Messaging) GetRequestThreadControlEvent() *RequestThreadControlEvent {
+	requestThreadControlEvent := &RequestThreadControlEvent{
+		Sender:    m.Sender,
+		Recipient: m.Recipient,
+		Timestamp: m.timestamp(),
+		eventBase: eventBase{raw: m},
+	}
+
+	if m.RequestThreadControl != nil {
+		requestThreadControlEvent.RequestedOwnerAppID = m.RequestThreadControl.RequestedOwnerAppID
+		requestThreadControlEvent.Metadata = m.RequestThreadControl.Metadata
+	}
+
+	return requestThreadControlEvent
+}
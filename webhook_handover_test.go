@@ -0,0 +1,255 @@
+package instabot
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const handoverTestPayload = `{
+	"object": "instagram",
+	"entry": [
+	  {
+		"id": "<IGID>",
+		"time": 1569262486134,
+		"messaging": [
+		  {
+			"sender": {
+			  "id": "<IGSID>"
+			},
+			"recipient": {
+			  "id": "<IGID>"
+			},
+			"timestamp": 1569262485349,
+			"pass_thread_control": {
+			  "new_owner_app_id": "123456789",
+			  "previous_owner_app_id": "987654321",
+			  "metadata": "<PASS_METADATA>"
+			}
+		  },
+		  {
+			"sender": {
+			  "id": "<IGSID>"
+			},
+			"recipient": {
+			  "id": "<IGID>"
+			},
+			"timestamp": 1569262485349,
+			"take_thread_control": {
+			  "previous_owner_app_id": "123456789",
+			  "new_owner_app_id": "987654321",
+			  "metadata": "<TAKE_METADATA>"
+			}
+		  },
+		  {
+			"sender": {
+			  "id": "<IGSID>"
+			},
+			"recipient": {
+			  "id": "<IGID>"
+			},
+			"timestamp": 1569262485349,
+			"request_thread_control": {
+			  "requested_owner_app_id": 123456789,
+			  "metadata": "<REQUEST_METADATA>"
+			}
+		  }
+		],
+		"standby": [
+		  {
+			"sender": {
+			  "id": "<IGSID>"
+			},
+			"recipient": {
+			  "id": "<IGID>"
+			},
+			"timestamp": 1569262485349,
+			"message": {
+			  "mid": "<MESSAGE_ID>",
+			  "text": "<MESSAGE_CONTENT>"
+			}
+		  }
+		]
+	  }
+	]
+}`
+
+func TestHandoverEvents(t *testing.T) {
+	e := new(WebhookEvent)
+	err := json.Unmarshal([]byte(handoverTestPayload), e)
+	assert.NoError(t, err)
+
+	entry := e.Entries[0]
+	sender := &Sender{ID: "<IGSID>"}
+	recipient := &Recipient{ID: "<IGID>"}
+	timestamp := time.Unix(0, 1569262485349*int64(time.Millisecond)).UTC()
+
+	assert.Equal(t, WebhookEventTypePassThreadControl, entry.Messaging[0].Type)
+	assert.Equal(t, &PassThreadControlEvent{
+		eventBase:          eventBase{raw: entry.Messaging[0]},
+		Sender:             sender,
+		Recipient:          recipient,
+		Timestamp:          timestamp,
+		NewOwnerAppID:      "123456789",
+		PreviousOwnerAppID: "987654321",
+		Metadata:           "<PASS_METADATA>",
+	}, entry.Messaging[0].GetPassThreadControlEvent())
+
+	assert.Equal(t, WebhookEventTypeTakeThreadControl, entry.Messaging[1].Type)
+	assert.Equal(t, &TakeThreadControlEvent{
+		eventBase:          eventBase{raw: entry.Messaging[1]},
+		Sender:             sender,
+		Recipient:          recipient,
+		Timestamp:          timestamp,
+		PreviousOwnerAppID: "123456789",
+		NewOwnerAppID:      "987654321",
+		Metadata:           "<TAKE_METADATA>",
+	}, entry.Messaging[1].GetTakeThreadControlEvent())
+
+	assert.Equal(t, WebhookEventTypeRequestThreadControl, entry.Messaging[2].Type)
+	assert.Equal(t, &RequestThreadControlEvent{
+		eventBase:           eventBase{raw: entry.Messaging[2]},
+		Sender:              sender,
+		Recipient:           recipient,
+		Timestamp:           timestamp,
+		RequestedOwnerAppID: "123456789",
+		Metadata:            "<REQUEST_METADATA>",
+	}, entry.Messaging[2].GetRequestThreadControlEvent())
+
+	for _, m := range entry.Messaging {
+		assert.False(t, m.Standby)
+	}
+
+	assert.Len(t, entry.Standby, 1)
+	assert.True(t, entry.Standby[0].Standby)
+	assert.Equal(t, WebhookEventTypeTextMessage, entry.Standby[0].Type)
+	assert.Equal(t, "<MESSAGE_CONTENT>", entry.Standby[0].GetTextMessageEvent().Text)
+}
+
+func TestDispatcherHandoverEvents(t *testing.T) {
+	e := new(WebhookEvent)
+	err := json.Unmarshal([]byte(handoverTestPayload), e)
+	assert.NoError(t, err)
+
+	called := map[string]int{}
+
+	d := NewDispatcher()
+	d.OnPassThreadControl(func(ctx context.Context, event *PassThreadControlEvent) error {
+		called["pass"]++
+
+		return nil
+	})
+	d.OnTakeThreadControl(func(ctx context.Context, event *TakeThreadControlEvent) error {
+		called["take"]++
+
+		return nil
+	})
+	d.OnRequestThreadControl(func(ctx context.Context, event *RequestThreadControlEvent) error {
+		called["request"]++
+
+		return nil
+	})
+	d.OnTextMessage(func(ctx context.Context, event *TextMessageEvent) error {
+		called["text"]++
+
+		return nil
+	})
+
+	// standby events are ignored without standby handler.
+	assert.NoError(t, d.HandleWebhookEvent(context.Background(), e))
+	assert.Equal(t, map[string]int{"pass": 1, "take": 1, "request": 1}, called)
+
+	d.OnStandby(func(ctx context.Context, m *Messaging) error {
+		assert.True(t, m.Standby)
+		called["standby"]++
+
+		return nil
+	})
+
+	assert.NoError(t, d.HandleWebhookEvent(context.Background(), e))
+	assert.Equal(t, map[string]int{"pass": 2, "take": 2, "request": 2, "standby": 1}, called)
+}
+
+func TestAppIDUnmarshalJSON(t *testing.T) {
+	testCases := []struct {
+		name    string
+		args    string
+		want    AppID
+		wantErr bool
+	}{
+		{
+			name: "string app id",
+			args: `"123456789"`,
+			want: "123456789",
+		},
+		{
+			name: "number app id",
+			args: `123456789`,
+			want: "123456789",
+		},
+		{
+			name: "empty string app id",
+			args: `""`,
+			want: "",
+		},
+		{
+			name: "non numeric string app id",
+			args: `"inbox"`,
+			want: "inbox",
+		},
+		{
+			name:    "invalid app id",
+			args:    `{}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var id AppID
+			err := json.Unmarshal([]byte(tc.args), &id)
+			if tc.wantErr {
+				assert.Error(t, err)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, id)
+		})
+	}
+}
+
+func TestHandoverEventStringAppID(t *testing.T) {
+	payload := `{
+		"object": "instagram",
+		"entry": [
+		  {
+			"id": "<IGID>",
+			"time": 1569262486134,
+			"messaging": [
+			  {
+				"sender": {"id": "<IGSID>"},
+				"recipient": {"id": "<IGID>"},
+				"timestamp": 1569262485349,
+				"take_thread_control": {
+				  "previous_owner_app_id": "",
+				  "new_owner_app_id": "inbox",
+				  "metadata": "<METADATA>"
+				}
+			  }
+			]
+		  }
+		]
+	}`
+
+	e := new(WebhookEvent)
+	assert.NoError(t, json.Unmarshal([]byte(payload), e))
+
+	takeThreadControl := e.Entries[0].Messaging[0].TakeThreadControl
+	assert.Equal(t, AppID(""), takeThreadControl.PreviousOwnerAppID)
+	assert.Equal(t, AppID("inbox"), takeThreadControl.NewOwnerAppID)
+}
@@ -2,6 +2,7 @@ package instabot
 
 import (
 	"encoding/json"
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -357,6 +358,118 @@ func TestWebhookEventType(t *testing.T) {
 				assert.Equal(t, "<CDN_URL>", message.Attachments[0].Payload.URL)
 			},
 		},
+		{
+			name: "message reply with share attachment event",
+			args: `{
+				"object":"instagram",
+				"entry":[
+				   {
+					  "id":"<IGID>",
+					  "time":1569262486134,
+					  "messaging":[
+						 {
+							"sender":{
+							   "id":"<IGSID>"
+							},
+							"recipient":{
+							   "id":"<IGID>"
+							},
+							"timestamp":1569262485349,
+							"message":{
+							   "mid":"<MESSAGE_ID>",
+							   "text":"<MESSAGE_CONTENT>",
+							   "reply_to":{
+								  "mid":"<REPLIED_MESSAGE_ID>"
+							   },
+							   "attachments":[
+								  {
+									 "type":"share",
+									 "payload":{
+										"url":"<CDN_URL>"
+									 }
+								  }
+							   ]
+							}
+						 }
+					  ]
+				   }
+				]
+			}`,
+			want: WebhookEventTypeShare,
+			afterEach: func(t *testing.T, event *WebhookEvent) {
+				share := event.Entries[0].Messaging[0].GetMessageShareEvent()
+				assert.Equal(t, "<MESSAGE_CONTENT>", share.Text)
+				assert.Equal(t, "<CDN_URL>", share.SharedPayloadURL)
+				assert.Len(t, share.Attachments, 1)
+			},
+		},
+		{
+			name: "template attachment event",
+			args: `{
+				"object":"instagram",
+				"entry":[
+				   {
+					  "id":"<IGID>",
+					  "time":1569262486134,
+					  "messaging":[
+						 {
+							"sender":{
+							   "id":"<IGSID>"
+							},
+							"recipient":{
+							   "id":"<IGID>"
+							},
+							"timestamp":1569262485349,
+							"message":{
+							   "mid":"<MESSAGE_ID>",
+							   "attachments":[
+								  {
+									 "type":"template",
+									 "payload":{
+										"template_type":"generic",
+										"elements":[
+										   {
+											  "title":"<TITLE>",
+											  "subtitle":"<SUBTITLE>",
+											  "image_url":"<IMAGE_URL>",
+											  "buttons":[
+												 {
+													"type":"postback",
+													"title":"<BUTTON_TITLE>",
+													"payload":"<BUTTON_PAYLOAD>"
+												 }
+											  ]
+										   }
+										]
+									 }
+								  }
+							   ]
+							}
+						 }
+					  ]
+				   }
+				]
+			}`,
+			want: WebhookEventTypeTemplate,
+			afterEach: func(t *testing.T, event *WebhookEvent) {
+				payload := event.Entries[0].Messaging[0].Message.Attachments[0].Payload
+				assert.Equal(t, "generic", payload.TemplateType)
+				assert.Equal(t, []*WebhookTemplateElement{
+					{
+						Title:    "<TITLE>",
+						Subtitle: "<SUBTITLE>",
+						ImageURL: "<IMAGE_URL>",
+						Buttons: []*WebhookTemplateButton{
+							{
+								Type:    "postback",
+								Title:   "<BUTTON_TITLE>",
+								Payload: "<BUTTON_PAYLOAD>",
+							},
+						},
+					},
+				}, payload.Elements)
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -373,3 +486,284 @@ func TestWebhookEventType(t *testing.T) {
 		})
 	}
 }
+
+const rawTestPayload = `{
+	"object": "instagram",
+	"entry": [
+	  {
+		"id": "<IGID>",
+		"time": 1569262486134,
+		"messaging": [
+		  {
+			"sender": {
+			  "id": "<IGSID>"
+			},
+			"recipient": {
+			  "id": "<IGID>"
+			},
+			"timestamp": 1569262485349,
+			"message": {
+			  "mid": "<MESSAGE_ID>",
+			  "text": "<MESSAGE_CONTENT>",
+			  "new_field": "<NEW_VALUE>"
+			}
+		  },
+		  {
+			"sender": {
+			  "id": "<IGSID>"
+			},
+			"recipient": {
+			  "id": "<IGID>"
+			},
+			"timestamp": 1569262485349,
+			"new_event": {
+			  "id": "<ID>"
+			}
+		  }
+		]
+	  }
+	]
+}`
+
+func TestWebhookEventRaw(t *testing.T) {
+	e := new(WebhookEvent)
+	err := json.Unmarshal([]byte(rawTestPayload), e)
+	assert.NoError(t, err)
+
+	assert.NotEmpty(t, e.Entries[0].Raw)
+
+	unclassified := e.Entries[0].Messaging[1]
+	assert.Equal(t, WebhookEventType(""), unclassified.Type)
+
+	var raw map[string]json.RawMessage
+	assert.NoError(t, json.Unmarshal(unclassified.Raw, &raw))
+	assert.JSONEq(t, `{"id": "<ID>"}`, string(raw["new_event"]))
+
+	// round trip keeps unknown fields.
+	j, err := json.Marshal(e)
+	assert.NoError(t, err)
+	assert.JSONEq(t, rawTestPayload, string(j))
+
+	decoded := new(WebhookEvent)
+	assert.NoError(t, json.Unmarshal(j, decoded))
+	assert.Equal(t, e.Entries[0].Messaging[0].Type, decoded.Entries[0].Messaging[0].Type)
+	assert.Equal(t, e.Entries[0].Messaging[0].Message, decoded.Entries[0].Messaging[0].Message)
+
+	again, err := json.Marshal(decoded)
+	assert.NoError(t, err)
+	assert.Equal(t, string(j), string(again))
+}
+
+func TestWebhookEventMarshalJSONTopLevelUnknownFields(t *testing.T) {
+	payload := `{"object": "instagram", "new_field": {"id": "<ID>"}, "entry": []}`
+
+	e := new(WebhookEvent)
+	assert.NoError(t, json.Unmarshal([]byte(payload), e))
+
+	j, err := json.Marshal(e)
+	assert.NoError(t, err)
+	assert.JSONEq(t, payload, string(j))
+}
+
+func TestWebhookEventMarshalJSONChangedFields(t *testing.T) {
+	e := new(WebhookEvent)
+	assert.NoError(t, json.Unmarshal([]byte(rawTestPayload), e))
+
+	m := e.Entries[0].Messaging[0]
+	m.Message.Text = "<REDACTED>"
+	e.Raw, e.Entries[0].Raw, m.Raw = nil, nil, nil
+
+	j, err := json.Marshal(e)
+	assert.NoError(t, err)
+
+	decoded := new(WebhookEvent)
+	assert.NoError(t, json.Unmarshal(j, decoded))
+	assert.Equal(t, "<REDACTED>", decoded.Entries[0].Messaging[0].Message.Text)
+	assert.NotEmpty(t, decoded.Entries[0].Messaging[1].Raw)
+}
+
+func TestWebhookEventMarshalJSONWithoutRaw(t *testing.T) {
+	e := &WebhookEvent{
+		Object: "instagram",
+		Entries: []*Entry{
+			{
+				ID:   "<IGID>",
+				Time: 1569262486134,
+				Messaging: []*Messaging{
+					{
+						Type:      WebhookEventTypeTextMessage,
+						Sender:    &Sender{ID: "<IGSID>"},
+						Recipient: &Recipient{ID: "<IGID>"},
+						Timestamp: 1569262485349,
+						Message: &WebhookMessage{
+							MID:  "<MESSAGE_ID>",
+							Text: "<MESSAGE_CONTENT>",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	j, err := json.Marshal(e)
+	assert.NoError(t, err)
+
+	decoded := new(WebhookEvent)
+	assert.NoError(t, json.Unmarshal(j, decoded))
+	assert.Equal(t, WebhookEventTypeTextMessage, decoded.Entries[0].Messaging[0].Type)
+	assert.Equal(t, "<MESSAGE_CONTENT>", decoded.Entries[0].Messaging[0].GetTextMessageEvent().Text)
+}
+
+func TestDecodeWebhookEvent(t *testing.T) {
+	testCases := []struct {
+		name    string
+		args    string
+		opts    []DecodeOption
+		wantErr error
+	}{
+		{
+			name: "it should ignore unknown fields by default",
+			args: rawTestPayload,
+		},
+		{
+			name:    "it should fail on unknown fields when strict",
+			args:    rawTestPayload,
+			opts:    []DecodeOption{StrictDecoding()},
+			wantErr: ErrUnknownWebhookField,
+		},
+		{
+			name: "it should fail on unclassified event when strict",
+			args: `{
+				"object": "instagram",
+				"entry": [
+				  {
+					"id": "<IGID>",
+					"time": 1569262486134,
+					"messaging": [
+					  {
+						"sender": {
+						  "id": "<IGSID>"
+						},
+						"recipient": {
+						  "id": "<IGID>"
+						},
+						"timestamp": 1569262485349
+					  }
+					]
+				  }
+				]
+			}`,
+			opts:    []DecodeOption{StrictDecoding()},
+			wantErr: ErrUnclassifiedWebhookEvent,
+		},
+		{
+			name: "it should decode unknown attachment type by default",
+			args: `{
+				"object": "instagram",
+				"entry": [
+				  {
+					"id": "<IGID>",
+					"time": 1569262486134,
+					"messaging": [
+					  {
+						"sender": {
+						  "id": "<IGSID>"
+						},
+						"recipient": {
+						  "id": "<IGID>"
+						},
+						"timestamp": 1569262485349,
+						"message": {
+						  "mid": "<MESSAGE_ID>",
+						  "text": "<MESSAGE_CONTENT>",
+						  "attachments": [
+							{
+							  "type": "unknown_new"
+							}
+						  ]
+						}
+					  }
+					]
+				  }
+				]
+			}`,
+		},
+		{
+			name: "it should fail on unknown attachment type when strict",
+			args: `{
+				"object": "instagram",
+				"entry": [
+				  {
+					"id": "<IGID>",
+					"time": 1569262486134,
+					"messaging": [
+					  {
+						"sender": {
+						  "id": "<IGSID>"
+						},
+						"recipient": {
+						  "id": "<IGID>"
+						},
+						"timestamp": 1569262485349,
+						"message": {
+						  "mid": "<MESSAGE_ID>",
+						  "text": "<MESSAGE_CONTENT>",
+						  "attachments": [
+							{
+							  "type": "unknown_new"
+							}
+						  ]
+						}
+					  }
+					]
+				  }
+				]
+			}`,
+			opts:    []DecodeOption{StrictDecoding()},
+			wantErr: ErrUnclassifiedWebhookEvent,
+		},
+		{
+			name: "it should decode known events when strict",
+			args: `{
+				"object": "instagram",
+				"entry": [
+				  {
+					"id": "<IGID>",
+					"time": 1569262486134,
+					"messaging": [
+					  {
+						"sender": {
+						  "id": "<IGSID>"
+						},
+						"recipient": {
+						  "id": "<IGID>"
+						},
+						"timestamp": 1569262485349,
+						"message": {
+						  "mid": "<MESSAGE_ID>",
+						  "text": "<MESSAGE_CONTENT>"
+						}
+					  }
+					]
+				  }
+				]
+			}`,
+			opts: []DecodeOption{StrictDecoding()},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e, err := DecodeWebhookEvent([]byte(tc.args), tc.opts...)
+			if tc.wantErr != nil {
+				assert.True(t, errors.Is(err, tc.wantErr))
+				assert.Nil(t, e)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.NotEmpty(t, e.Entries)
+		})
+	}
+}
@@ -0,0 +1,312 @@
+package instabot
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sync"
+)
+
+// default worker pool values.
+const (
+	DefaultWorkerPoolWorkers   = 10
+	DefaultWorkerPoolQueueSize = 100
+)
+
+// WorkerPool defines asynchronous webhook event handler.
+// Every messaging, standby and change of a webhook event is queued as a separate
+// webhook event and handled concurrently across conversations,
+// while events of the same conversation are handled strictly in order,
+// echoes of messages sent to a user included.
+// Comments are ordered per author, mentions and story insights per media.
+//
+// Used as the event handler of a WebhookHandler it lets deliveries
+// be acknowledged as soon as they are queued.
+type WorkerPool struct {
+	handler      WebhookEventHandler
+	workers      int
+	queueSize    int
+	errorHandler func(ctx context.Context, event *WebhookEvent, err error)
+
+	mu        sync.RWMutex
+	enqueueMu sync.Mutex
+	closed    bool
+	queues    []chan *WebhookEvent
+	wg        sync.WaitGroup
+}
+
+// compile time interface implementation check.
+var _ WebhookEventHandler = (*WorkerPool)(nil)
+
+// WorkerPoolOption defines optional argument for new worker pool construction.
+type WorkerPoolOption func(*WorkerPool) error
+
+// WithWorkers sets number of workers handling events concurrently.
+func WithWorkers(workers int) WorkerPoolOption {
+	return func(p *WorkerPool) error {
+		if workers <= 0 {
+			return ErrInvalidWorkers
+		}
+
+		p.workers = workers
+
+		return nil
+	}
+}
+
+// WithQueueSize sets number of events each worker can hold pending.
+func WithQueueSize(queueSize int) WorkerPoolOption {
+	return func(p *WorkerPool) error {
+		if queueSize <= 0 {
+			return ErrInvalidQueueSize
+		}
+
+		p.queueSize = queueSize
+
+		return nil
+	}
+}
+
+// WithErrorHandler sets function called with errors returned by
+// the wrapped handler, and its panics wrapped in ErrHandlerPanic,
+// errors are dropped otherwise.
+func WithErrorHandler(errorHandler func(ctx context.Context, event *WebhookEvent, err error)) WorkerPoolOption {
+	return func(p *WorkerPool) error {
+		p.errorHandler = errorHandler
+
+		return nil
+	}
+}
+
+// NewWorkerPool returns a new started worker pool handling queued events with handler.
+func NewWorkerPool(handler WebhookEventHandler, options ...WorkerPoolOption) (*WorkerPool, error) {
+	if handler == nil {
+		return nil, ErrMissingWebhookEventHandler
+	}
+
+	p := &WorkerPool{
+		handler:   handler,
+		workers:   DefaultWorkerPoolWorkers,
+		queueSize: DefaultWorkerPoolQueueSize,
+	}
+
+	for _, option := range options {
+		if err := option(p); err != nil {
+			return nil, err
+		}
+	}
+
+	p.queues = make([]chan *WebhookEvent, p.workers)
+	for i := range p.queues {
+		p.queues[i] = make(chan *WebhookEvent, p.queueSize)
+
+		p.wg.Add(1)
+		go p.work(p.queues[i])
+	}
+
+	return p, nil
+}
+
+// HandleWebhookEvent queues every messaging, standby and change of the event
+// without waiting for them to be handled. The event is queued entirely or not at all,
+// ErrQueueFull is returned when the queue of any of its senders lacks room,
+// ErrWorkerPoolClosed after Shutdown is called.
+func (p *WorkerPool) HandleWebhookEvent(ctx context.Context, event *WebhookEvent) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		return ErrWorkerPoolClosed
+	}
+
+	items := p.split(event)
+
+	// enqueuing is serialized, so that room checked for the whole event
+	// is not taken by a concurrent one before it is queued.
+	p.enqueueMu.Lock()
+	defer p.enqueueMu.Unlock()
+
+	pending := make(map[int]int)
+	for _, item := range items {
+		pending[item.queue]++
+	}
+
+	for queue, n := range pending {
+		if len(p.queues[queue])+n > cap(p.queues[queue]) {
+			return ErrQueueFull
+		}
+	}
+
+	for _, item := range items {
+		p.queues[item.queue] <- item.event
+	}
+
+	return nil
+}
+
+// queuedEvent defines single event webhook event and its target queue.
+type queuedEvent struct {
+	queue int
+	event *WebhookEvent
+}
+
+// split splits event into single event webhook events,
+// each one targeting the queue of its sender.
+func (p *WorkerPool) split(event *WebhookEvent) []*queuedEvent {
+	var items []*queuedEvent
+
+	for _, entry := range event.Entries {
+		for _, m := range entry.Messaging {
+			items = append(items, &queuedEvent{
+				queue: p.queueIndex(m.conversationKey(entry.ID)),
+				event: &WebhookEvent{
+					Object: event.Object,
+					Entries: []*Entry{
+						{
+							ID:        entry.ID,
+							Time:      entry.Time,
+							Messaging: []*Messaging{m},
+						},
+					},
+				},
+			})
+		}
+
+		for _, m := range entry.Standby {
+			items = append(items, &queuedEvent{
+				queue: p.queueIndex(m.conversationKey(entry.ID)),
+				event: &WebhookEvent{
+					Object: event.Object,
+					Entries: []*Entry{
+						{
+							ID:      entry.ID,
+							Time:    entry.Time,
+							Standby: []*Messaging{m},
+						},
+					},
+				},
+			})
+		}
+
+		for _, c := range entry.Changes {
+			items = append(items, &queuedEvent{
+				queue: p.queueIndex(c.queueKey(entry.ID)),
+				event: &WebhookEvent{
+					Object: event.Object,
+					Entries: []*Entry{
+						{
+							ID:      entry.ID,
+							Time:    entry.Time,
+							Changes: []*Change{c},
+						},
+					},
+				},
+			})
+		}
+	}
+
+	return items
+}
+
+func (p *WorkerPool) queueIndex(key string) int {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+
+	return int(h.Sum32() % uint32(len(p.queues)))
+}
+
+func (p *WorkerPool) work(queue <-chan *WebhookEvent) {
+	defer p.wg.Done()
+
+	for event := range queue {
+		ctx := context.Background()
+
+		if err := p.handle(ctx, event); err != nil && p.errorHandler != nil {
+			p.errorHandler(ctx, event, err)
+		}
+	}
+}
+
+// handle handles event recovering from handler panics,
+// so that a panicking handler never stops the worker.
+func (p *WorkerPool) handle(ctx context.Context, event *WebhookEvent) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", ErrHandlerPanic, r)
+		}
+	}()
+
+	return p.handler.HandleWebhookEvent(ctx, event)
+}
+
+// QueueDepth returns number of queued events waiting to be handled.
+func (p *WorkerPool) QueueDepth() int {
+	depth := 0
+	for _, queue := range p.queues {
+		depth += len(queue)
+	}
+
+	return depth
+}
+
+// Shutdown stops accepting new events and waits until every queued
+// and in-flight event is handled or the context is done.
+func (p *WorkerPool) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+
+		for _, queue := range p.queues {
+			close(queue)
+		}
+	}
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// conversationKey returns id of the user of the conversation, the sender
+// of the event or, for echoes, the recipient of the echoed message,
+// so that messages and echoes of replies to them are handled in order.
+func (m *Messaging) conversationKey(fallback string) string {
+	if m.Type == WebhookEventTypeEcho {
+		if m.Recipient != nil {
+			return m.Recipient.ID
+		}
+
+		return fallback
+	}
+
+	if m.Sender != nil {
+		return m.Sender.ID
+	}
+
+	return fallback
+}
+
+// queueKey returns key ordering the change, comments are ordered per author,
+// mentions and story insights per media, so that account level changes
+// are spread across workers instead of all queuing for the account.
+func (c *Change) queueKey(fallback string) string {
+	switch {
+	case c.Comment != nil && c.Comment.From != nil:
+		return c.Comment.From.ID
+	case c.Mention != nil && c.Mention.MediaID != "":
+		return "media:" + c.Mention.MediaID
+	case c.StoryInsights != nil && c.StoryInsights.MediaID != "":
+		return "media:" + c.StoryInsights.MediaID
+	}
+
+	return fallback
+}
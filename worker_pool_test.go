@@ -0,0 +1,327 @@
+package instabot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestWebhookEvent(senderID string, mids ...string) *WebhookEvent {
+	entry := &Entry{ID: "<IGID>"}
+	for _, mid := range mids {
+		entry.Messaging = append(entry.Messaging, &Messaging{
+			Type:      WebhookEventTypeTextMessage,
+			Sender:    &Sender{ID: senderID},
+			Recipient: &Recipient{ID: "<IGID>"},
+			Message:   &WebhookMessage{MID: mid, Text: mid},
+		})
+	}
+
+	return &WebhookEvent{Object: "instagram", Entries: []*Entry{entry}}
+}
+
+func TestNewWorkerPool(t *testing.T) {
+	handler := WebhookEventHandlerFunc(func(ctx context.Context, event *WebhookEvent) error {
+		return nil
+	})
+
+	testCases := []struct {
+		name    string
+		handler WebhookEventHandler
+		options []WorkerPoolOption
+		wantErr error
+	}{
+		{
+			name:    "it should return error, when handler is not given",
+			wantErr: ErrMissingWebhookEventHandler,
+		},
+		{
+			name:    "it should return error, when workers is invalid",
+			handler: handler,
+			options: []WorkerPoolOption{WithWorkers(0)},
+			wantErr: ErrInvalidWorkers,
+		},
+		{
+			name:    "it should return error, when queue size is invalid",
+			handler: handler,
+			options: []WorkerPoolOption{WithQueueSize(-1)},
+			wantErr: ErrInvalidQueueSize,
+		},
+		{
+			name:    "it should return worker pool",
+			handler: handler,
+			options: []WorkerPoolOption{WithWorkers(2), WithQueueSize(5)},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := NewWorkerPool(tc.handler, tc.options...)
+			if tc.wantErr != nil {
+				assert.Equal(t, tc.wantErr, err)
+				assert.Nil(t, p)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.NoError(t, p.Shutdown(context.Background()))
+		})
+	}
+}
+
+func TestWorkerPoolOrderPerSender(t *testing.T) {
+	var mu sync.Mutex
+	got := map[string][]string{}
+
+	p, err := NewWorkerPool(
+		WebhookEventHandlerFunc(func(ctx context.Context, event *WebhookEvent) error {
+			assert.Len(t, event.Entries, 1)
+			assert.Len(t, event.Entries[0].Messaging, 1)
+
+			m := event.Entries[0].Messaging[0]
+
+			mu.Lock()
+			got[m.Sender.ID] = append(got[m.Sender.ID], m.Message.MID)
+			mu.Unlock()
+
+			return nil
+		}),
+		WithWorkers(4),
+		WithQueueSize(100),
+	)
+	assert.NoError(t, err)
+
+	want := map[string][]string{}
+	for i := 0; i < 20; i++ {
+		for _, sender := range []string{"<IGSID_1>", "<IGSID_2>", "<IGSID_3>"} {
+			mid := fmt.Sprintf("%s_%d", sender, i)
+			want[sender] = append(want[sender], mid)
+
+			err := p.HandleWebhookEvent(context.Background(), newTestWebhookEvent(sender, mid))
+			assert.NoError(t, err)
+		}
+	}
+
+	assert.NoError(t, p.Shutdown(context.Background()))
+	assert.Equal(t, want, got)
+	assert.Equal(t, ErrWorkerPoolClosed, p.HandleWebhookEvent(context.Background(), newTestWebhookEvent("<IGSID_1>", "late")))
+}
+
+func TestWorkerPoolQueueFull(t *testing.T) {
+	started := make(chan struct{}, 10)
+	release := make(chan struct{})
+
+	var mu sync.Mutex
+	var handled int
+
+	p, err := NewWorkerPool(
+		WebhookEventHandlerFunc(func(ctx context.Context, event *WebhookEvent) error {
+			started <- struct{}{}
+			<-release
+
+			mu.Lock()
+			handled++
+			mu.Unlock()
+
+			return nil
+		}),
+		WithWorkers(1),
+		WithQueueSize(1),
+	)
+	assert.NoError(t, err)
+
+	assert.NoError(t, p.HandleWebhookEvent(context.Background(), newTestWebhookEvent("<IGSID>", "1")))
+	<-started
+
+	assert.NoError(t, p.HandleWebhookEvent(context.Background(), newTestWebhookEvent("<IGSID>", "2")))
+	assert.Equal(t, 1, p.QueueDepth())
+
+	assert.Equal(t, ErrQueueFull, p.HandleWebhookEvent(context.Background(), newTestWebhookEvent("<IGSID>", "3")))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, p.Shutdown(ctx))
+
+	close(release)
+	assert.NoError(t, p.Shutdown(context.Background()))
+	assert.Equal(t, 0, p.QueueDepth())
+	assert.Equal(t, 2, handled)
+}
+
+func TestWorkerPoolQueueFullBatch(t *testing.T) {
+	started := make(chan struct{}, 10)
+	release := make(chan struct{})
+
+	var mu sync.Mutex
+	var handled []string
+
+	p, err := NewWorkerPool(
+		WebhookEventHandlerFunc(func(ctx context.Context, event *WebhookEvent) error {
+			started <- struct{}{}
+			<-release
+
+			mu.Lock()
+			handled = append(handled, event.Entries[0].Messaging[0].Message.MID)
+			mu.Unlock()
+
+			return nil
+		}),
+		WithWorkers(1),
+		WithQueueSize(2),
+	)
+	assert.NoError(t, err)
+
+	assert.NoError(t, p.HandleWebhookEvent(context.Background(), newTestWebhookEvent("<IGSID>", "1")))
+	<-started
+
+	assert.NoError(t, p.HandleWebhookEvent(context.Background(), newTestWebhookEvent("<IGSID>", "2")))
+	assert.Equal(t, 1, p.QueueDepth())
+
+	// batch partly fitting the queue is rejected without queuing any of it.
+	assert.Equal(t, ErrQueueFull, p.HandleWebhookEvent(context.Background(), newTestWebhookEvent("<IGSID>", "3", "4")))
+	assert.Equal(t, 1, p.QueueDepth())
+
+	assert.NoError(t, p.HandleWebhookEvent(context.Background(), newTestWebhookEvent("<IGSID>", "5")))
+
+	close(release)
+	assert.NoError(t, p.Shutdown(context.Background()))
+	assert.Equal(t, []string{"1", "2", "5"}, handled)
+}
+
+func TestWorkerPoolErrorHandler(t *testing.T) {
+	errHandler := errors.New("handler error")
+
+	var mu sync.Mutex
+	var got []error
+
+	p, err := NewWorkerPool(
+		WebhookEventHandlerFunc(func(ctx context.Context, event *WebhookEvent) error {
+			return errHandler
+		}),
+		WithErrorHandler(func(ctx context.Context, event *WebhookEvent, err error) {
+			mu.Lock()
+			got = append(got, err)
+			mu.Unlock()
+		}),
+	)
+	assert.NoError(t, err)
+
+	assert.NoError(t, p.HandleWebhookEvent(context.Background(), newTestWebhookEvent("<IGSID>", "1", "2")))
+	assert.NoError(t, p.Shutdown(context.Background()))
+	assert.Equal(t, []error{errHandler, errHandler}, got)
+}
+
+func TestWorkerPoolRecoversChangeHandlerPanic(t *testing.T) {
+	var mu sync.Mutex
+	var got []error
+
+	d := NewDispatcher()
+	d.OnComment(func(ctx context.Context, event *CommentEvent) error {
+		panic("comment handler panic")
+	})
+
+	p, err := NewWorkerPool(
+		d,
+		WithWorkers(1),
+		WithErrorHandler(func(ctx context.Context, event *WebhookEvent, err error) {
+			mu.Lock()
+			got = append(got, err)
+			mu.Unlock()
+		}),
+	)
+	assert.NoError(t, err)
+
+	event, err := DecodeWebhookEvent([]byte(`{
+		"object": "instagram",
+		"entry": [
+		  {
+			"id": "<IGID>",
+			"time": 1569262486134,
+			"changes": [
+			  {
+				"field": "comments",
+				"value": {
+				  "id": "<COMMENT_ID>",
+				  "text": "<COMMENT_TEXT>"
+				}
+			  },
+			  {
+				"field": "comments",
+				"value": {
+				  "id": "<COMMENT_ID_2>",
+				  "text": "<COMMENT_TEXT>"
+				}
+			  }
+			]
+		  }
+		]
+	}`))
+	assert.NoError(t, err)
+
+	assert.NoError(t, p.HandleWebhookEvent(context.Background(), event))
+	assert.NoError(t, p.Shutdown(context.Background()))
+
+	assert.Len(t, got, 2)
+	for _, err := range got {
+		assert.True(t, errors.Is(err, ErrHandlerPanic))
+	}
+}
+
+func TestWorkerPoolQueueKeys(t *testing.T) {
+	testCases := []struct {
+		name string
+		key  string
+		want string
+	}{
+		{
+			name: "message is keyed by sender",
+			key: (&Messaging{
+				Type:      WebhookEventTypeTextMessage,
+				Sender:    &Sender{ID: "<IGSID>"},
+				Recipient: &Recipient{ID: "<IGID>"},
+			}).conversationKey("<IGID>"),
+			want: "<IGSID>",
+		},
+		{
+			name: "echo is keyed by recipient",
+			key: (&Messaging{
+				Type:      WebhookEventTypeEcho,
+				Sender:    &Sender{ID: "<IGID>"},
+				Recipient: &Recipient{ID: "<IGSID>"},
+			}).conversationKey("<IGID>"),
+			want: "<IGSID>",
+		},
+		{
+			name: "comment is keyed by author",
+			key:  (&Change{Comment: &Comment{From: &CommentAuthor{ID: "<IGSID>"}}}).queueKey("<IGID>"),
+			want: "<IGSID>",
+		},
+		{
+			name: "mention is keyed by media",
+			key:  (&Change{Mention: &Mention{MediaID: "<MEDIA_ID>"}}).queueKey("<IGID>"),
+			want: "media:<MEDIA_ID>",
+		},
+		{
+			name: "story insights are keyed by media",
+			key:  (&Change{StoryInsights: &StoryInsights{MediaID: "<MEDIA_ID>"}}).queueKey("<IGID>"),
+			want: "media:<MEDIA_ID>",
+		},
+		{
+			name: "unknown change falls back to account",
+			key:  (&Change{}).queueKey("<IGID>"),
+			want: "<IGID>",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.key)
+		})
+	}
+}